@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,149 +12,305 @@ import (
 	"strconv"
 )
 
-func (c *Client) CreateAnnotations(domain string, payload string) (resp []byte, err error) {
+// ErrPreconditionFailed is returned by the write methods when the annotations
+// were changed since the given revision was read.
+var ErrPreconditionFailed = errors.New(strconv.Itoa(http.StatusPreconditionFailed))
+
+// Sends If-Match so that the write only happens if the annotations are still
+// at the given revision. An empty revision writes unconditionally.
+func setRevision(req *http.Request, revision string) {
+	if revision != "" {
+		req.Header.Set(headerIfMatch, revision)
+	}
+}
+
+// Returns the error for a write request that failed with the status code.
+func writeError(statusCode int) error {
+	if statusCode == http.StatusPreconditionFailed {
+		return ErrPreconditionFailed
+	}
+	return errors.New(strconv.Itoa(statusCode))
+}
+
+// Asks the server to only validate the write, without committing it.
+func setDryRun(url *url.URL, dryRun bool) {
+	if !dryRun {
+		return
+	}
+	q := url.Query()
+	q.Set("dryRun", "true")
+	url.RawQuery = q.Encode()
+}
+
+// Creates new root keys. Returns the revision of the annotations after the
+// write, if the server sends one.
+func (c *Client) CreateAnnotations(ctx context.Context, domain string, payload string, revision string) (resp []byte, newRevision string, err error) {
+	return c.createAnnotations(ctx, domain, payload, revision, false)
+}
+
+// Validates the creation of new root keys on the server without committing
+// it. Returns the response body of a rejected request.
+func (c *Client) DryRunCreateAnnotations(ctx context.Context, domain string, payload string) (resp []byte, err error) {
+	resp, _, err = c.createAnnotations(ctx, domain, payload, "", true)
+	return resp, err
+}
+
+func (c *Client) createAnnotations(ctx context.Context, domain string, payload string, revision string, dryRun bool) (resp []byte, newRevision string, err error) {
 	path, err := url.JoinPath(c.Endpoint, "domains", domain, "annotations")
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	url, err := url.Parse(path)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, url.String(), bytes.NewBuffer([]byte(payload)))
+	setDryRun(url, dryRun)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url.String(), bytes.NewBuffer([]byte(payload)))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+	setRevision(req, revision)
 
 	httpResponse, err := c.execute(req)
 	if err != nil {
-		return []byte(fmt.Sprintf("Client HTTP Error %s", err.Error())), err
+		return []byte(fmt.Sprintf("Client HTTP Error %s", err.Error())), "", err
 	}
 
 	defer httpResponse.Body.Close()
 	if httpResponse.StatusCode >= 400 {
 		body, _ := io.ReadAll(httpResponse.Body)
-		return body, errors.New(strconv.Itoa(httpResponse.StatusCode))
+		return body, "", writeError(httpResponse.StatusCode)
 	}
-	return nil, nil
+	return nil, httpResponse.Header.Get(headerETag), nil
 }
 
-func (c *Client) ReadAnnotations(domain string, payload []byte) (resp map[string]any, err error) {
+// Reads the given root keys. Returns the revision of the annotations, taken
+// from the ETag header, to be used with If-Match on later writes. The server
+// responds with 404 both if none of the keys exist and if the domain itself
+// does not exist, in which case the body is {"err": "domain not found"} and
+// ErrDomainNotFound is returned.
+func (c *Client) ReadAnnotations(ctx context.Context, domain string, payload []byte) (resp map[string]any, revision string, err error) {
 	path, err := url.JoinPath(c.Endpoint, "domains", domain, "annotations")
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	url, err := url.Parse(path)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	q := url.Query()
 	q.Set("filter", string(payload))
 	url.RawQuery = q.Encode()
 
-	req, err := http.NewRequest(http.MethodGet, url.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	var httpResp *http.Response
 	if httpResp, err = c.execute(req); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	if httpResp.StatusCode != http.StatusOK {
 		// If no annotations are found, dont return error,
 		// so that TF can proceed with plan with empty annotations as input.
+		// The same status is returned when the domain itself is missing,
+		// which the server tells apart in the body.
 		if httpResp.StatusCode == http.StatusNotFound {
-			return nil, nil
+			defer httpResp.Body.Close()
+			body, err := io.ReadAll(httpResp.Body)
+			if err != nil {
+				return nil, "", err
+			}
+			if isDomainNotFound(body) {
+				return nil, "", ErrDomainNotFound
+			}
+			return nil, "", nil
 		}
 
-		return nil, handleErrorResponse(httpResp)
+		return nil, "", handleErrorResponse(httpResp)
 	}
 
 	defer httpResp.Body.Close()
 	body, err := io.ReadAll(httpResp.Body)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	var metadata AnnotationsResponse
 	if err := json.Unmarshal(body, &metadata); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
+	revision = httpResp.Header.Get(headerETag)
 	if metadata.Domain.Metadata.Annotations == nil {
-		return nil, nil
+		return nil, revision, nil
 	}
 
-	return metadata.Domain.Metadata.Annotations, nil
+	return metadata.Domain.Metadata.Annotations, revision, nil
+}
+
+// Replaces the values of existing root keys. Sends If-Match with the revision,
+// unless it is empty, and returns the revision after the write.
+func (c *Client) UpdateAnnotations(ctx context.Context, domain string, payload []byte, revision string) (resp []byte, newRevision string, err error) {
+	return c.updateAnnotations(ctx, domain, payload, revision, false)
 }
 
-func (c *Client) UpdateAnnotations(domain string, payload []byte) (resp []byte, err error) {
+// Validates replacing the values of existing root keys on the server without
+// committing it. Returns the response body of a rejected request.
+func (c *Client) DryRunUpdateAnnotations(ctx context.Context, domain string, payload []byte) (resp []byte, err error) {
+	resp, _, err = c.updateAnnotations(ctx, domain, payload, "", true)
+	return resp, err
+}
+
+func (c *Client) updateAnnotations(ctx context.Context, domain string, payload []byte, revision string, dryRun bool) (resp []byte, newRevision string, err error) {
 	path, err := url.JoinPath(c.Endpoint, "domains", domain, "annotations")
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	url, err := url.Parse(path)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	req, err := http.NewRequest(http.MethodPatch, url.String(), bytes.NewBuffer(payload))
+	setDryRun(url, dryRun)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, url.String(), bytes.NewBuffer(payload))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+	setRevision(req, revision)
 
 	httpResponse, err := c.execute(req)
 	if err != nil {
-		return []byte(fmt.Sprintf("Client HTTP Error %s", err.Error())), err
+		return []byte(fmt.Sprintf("Client HTTP Error %s", err.Error())), "", err
 	}
 
 	defer httpResponse.Body.Close()
 	body, _ := io.ReadAll(httpResponse.Body)
 
 	if httpResponse.StatusCode >= 400 {
-		return body, errors.New(strconv.Itoa(httpResponse.StatusCode))
+		return body, "", writeError(httpResponse.StatusCode)
 	}
 
-	return body, nil
+	return body, httpResponse.Header.Get(headerETag), nil
 }
 
-func (c *Client) DeleteAnnotations(domain string, payload []byte) (resp []byte, err error) {
+// ErrJSONPatchUnsupported is returned by PatchAnnotations when JSON Patch is
+// not enabled or the server does not accept JSON Patch requests. The caller
+// should fall back to CreateAnnotations, UpdateAnnotations and
+// DeleteAnnotations.
+var ErrJSONPatchUnsupported = errors.New("server does not support JSON Patch")
+
+// Returns false if JSON Patch is not enabled, or once the server has rejected
+// a JSON Patch request as unsupported.
+func (c *Client) SupportsJSONPatch() bool {
+	return c.JSONPatch && !c.jsonPatchUnsupported.Load()
+}
+
+// Applies an RFC 6902 JSON Patch to the annotations of a domain in a single
+// request. Paths are relative to the annotations object, e.g.
+// "/common~1devops/status". Sends If-Match with the revision, unless it is
+// empty, and returns the revision after the write.
+func (c *Client) PatchAnnotations(ctx context.Context, domain string, patch []byte, revision string) (resp []byte, newRevision string, err error) {
+	if !c.SupportsJSONPatch() {
+		return nil, "", ErrJSONPatchUnsupported
+	}
+
 	path, err := url.JoinPath(c.Endpoint, "domains", domain, "annotations")
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	url, err := url.Parse(path)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, url.String(), bytes.NewBuffer(patch))
+	if err != nil {
+		return nil, "", err
+	}
+	setRevision(req, revision)
+	req.Header.Set(headerContent, mediaTypeJSONPatch)
+
+	httpResponse, err := c.execute(req)
+	if err != nil {
+		return []byte(fmt.Sprintf("Client HTTP Error %s", err.Error())), "", err
+	}
+
+	defer httpResponse.Body.Close()
+	body, _ := io.ReadAll(httpResponse.Body)
+
+	// Only these say that the request was not understood as a JSON Patch.
+	// Any other failure, e.g. a validation error, would fail the same way
+	// when sent as separate requests.
+	switch httpResponse.StatusCode {
+	case http.StatusUnsupportedMediaType, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		c.jsonPatchUnsupported.Store(true)
+		return body, "", ErrJSONPatchUnsupported
+	}
+
+	if httpResponse.StatusCode >= 400 {
+		return body, "", writeError(httpResponse.StatusCode)
+	}
+
+	return body, httpResponse.Header.Get(headerETag), nil
+}
+
+// Deletes root keys. Sends If-Match with the revision, unless it is empty, and
+// returns the revision after the write.
+func (c *Client) DeleteAnnotations(ctx context.Context, domain string, payload []byte, revision string) (resp []byte, newRevision string, err error) {
+	return c.deleteAnnotations(ctx, domain, payload, revision, false)
+}
+
+// Validates the deletion of root keys on the server without committing it.
+// Returns the response body of a rejected request.
+func (c *Client) DryRunDeleteAnnotations(ctx context.Context, domain string, payload []byte) (resp []byte, err error) {
+	resp, _, err = c.deleteAnnotations(ctx, domain, payload, "", true)
+	return resp, err
+}
+
+func (c *Client) deleteAnnotations(ctx context.Context, domain string, payload []byte, revision string, dryRun bool) (resp []byte, newRevision string, err error) {
+	path, err := url.JoinPath(c.Endpoint, "domains", domain, "annotations")
+	if err != nil {
+		return nil, "", err
+	}
+
+	url, err := url.Parse(path)
+	if err != nil {
+		return nil, "", err
 	}
 
 	q := url.Query()
 	q.Set("filter", string(payload))
 	url.RawQuery = q.Encode()
 
-	req, err := http.NewRequest(http.MethodDelete, url.String(), nil)
+	setDryRun(url, dryRun)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url.String(), nil)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
+	setRevision(req, revision)
 
 	httpResponse, err := c.execute(req)
 	if err != nil {
-		return []byte(fmt.Sprintf("Client HTTP Error %s", err.Error())), err
+		return []byte(fmt.Sprintf("Client HTTP Error %s", err.Error())), "", err
 	}
 
 	defer httpResponse.Body.Close()
 	body, _ := io.ReadAll(httpResponse.Body)
 
 	if httpResponse.StatusCode >= 400 {
-		return body, errors.New(strconv.Itoa(httpResponse.StatusCode))
+		return body, "", writeError(httpResponse.StatusCode)
 	}
-	return body, nil
+	return body, httpResponse.Header.Get(headerETag), nil
 }
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchAnnotationsUnsupported(t *testing.T) {
+	for _, status := range []int{http.StatusUnsupportedMediaType, http.StatusMethodNotAllowed, http.StatusNotImplemented} {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, mediaTypeJSONPatch, r.Header.Get(headerContent))
+			w.WriteHeader(status)
+		})
+		client.JSONPatch = true
+
+		_, _, err := client.PatchAnnotations(context.Background(), "example.com", []byte(`[]`), "1")
+		assert.ErrorIs(t, err, ErrJSONPatchUnsupported, status)
+		assert.False(t, client.SupportsJSONPatch(), status)
+	}
+}
+
+func TestPatchAnnotationsRejected(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity} {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"err":"rejected"}`, status)
+		})
+		client.JSONPatch = true
+
+		body, _, err := client.PatchAnnotations(context.Background(), "example.com", []byte(`[]`), "1")
+		require.Error(t, err, status)
+		assert.NotErrorIs(t, err, ErrJSONPatchUnsupported, status)
+		assert.Contains(t, string(body), "rejected", status)
+		assert.True(t, client.SupportsJSONPatch(), status)
+	}
+}
+
+func TestPatchAnnotationsPreconditionFailed(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "1", r.Header.Get(headerIfMatch))
+		w.WriteHeader(http.StatusPreconditionFailed)
+	})
+	client.JSONPatch = true
+
+	_, _, err := client.PatchAnnotations(context.Background(), "example.com", []byte(`[]`), "1")
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+}
+
+func TestReadAnnotationsKeysNotFound(t *testing.T) {
+	for _, body := range []string{`{"err":"annotations not found"}`, `not found`, ``} {
+		requests := 0
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			assert.Equal(t, "/domains/example.com/annotations", r.URL.Path)
+			http.Error(w, body, http.StatusNotFound)
+		})
+
+		annotations, revision, err := client.ReadAnnotations(context.Background(), "example.com", []byte(`["common/a"]`))
+		require.NoError(t, err, body)
+		assert.Nil(t, annotations, body)
+		assert.Empty(t, revision, body)
+		assert.Equal(t, 1, requests, "No other endpoint should be requested.")
+	}
+}
+
+func TestReadAnnotationsDomainNotFound(t *testing.T) {
+	requests := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, `{"err":"domain not found"}`, http.StatusNotFound)
+	})
+
+	_, _, err := client.ReadAnnotations(context.Background(), "example.com", []byte(`["common/a"]`))
+	assert.ErrorIs(t, err, ErrDomainNotFound)
+	assert.Equal(t, 1, requests)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"net/url"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,7 +18,10 @@ const (
 	headerAccept        = "Accept"
 	headerAuthorization = "Authorization"
 	headerContent       = "Content-Type"
+	headerETag          = "ETag"
+	headerIfMatch       = "If-Match"
 	mediaTypeJSON       = "application/json"
+	mediaTypeJSONPatch  = "application/json-patch+json"
 	mediaTypeURLForm    = "application/x-www-form-urlencoded"
 	rateLimit           = 100 * time.Millisecond
 )
@@ -25,24 +30,64 @@ const (
 type Client struct {
 	Endpoint string
 	client   *http.Client
+
+	// Send annotation changes as a single JSON Patch request. Off by default,
+	// since not every server supports JSON Patch.
+	JSONPatch bool
+
+	// Set once the server has rejected a JSON Patch request as unsupported.
+	jsonPatchUnsupported atomic.Bool
+
+	// One lock per domain, held while its annotations are read and written.
+	domainLocks sync.Map
+}
+
+// Serializes the writes of this client to the annotations of a domain, so
+// that concurrent operations of one apply do not invalidate each other's
+// revisions. Returns the function releasing the lock, or the error of the
+// context if it is done before the lock is acquired.
+func (c *Client) LockDomain(ctx context.Context, domain string) (unlock func(), err error) {
+	lock, _ := c.domainLocks.LoadOrStore(domain, make(chan struct{}, 1))
+	sem := lock.(chan struct{})
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 type rateLimitedTransport struct {
 	delegate http.RoundTripper
+	// The earliest time the next request may be sent.
 	throttle time.Time
 	sync.Mutex
 }
 
 func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Only reserving the send time is serialized, so that a slow request does
+	// not hold up the others, nor their cancellation.
 	t.Lock()
-	defer t.Unlock()
-
-	if t.throttle.After(time.Now()) {
-		delta := time.Until(t.throttle)
-		time.Sleep(delta)
+	send := time.Now()
+	if t.throttle.After(send) {
+		send = t.throttle
+	}
+	t.throttle = send.Add(rateLimit)
+	t.Unlock()
+
+	// Wait for the rate limit, unless the request is cancelled first.
+	if wait := time.Until(send); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
 	}
 
-	t.throttle = time.Now().Add(rateLimit)
 	return t.delegate.RoundTrip(req)
 }
 
@@ -61,8 +106,9 @@ func NewClient(endpoint string) (*Client, error) {
 
 	return &Client{
 		Endpoint: endpoint,
+		// Requests are bounded by the deadline of their context, derived from
+		// the timeouts of the calling resource or data source.
 		client: &http.Client{
-			Timeout: time.Second * 30,
 			Transport: &rateLimitedTransport{
 				delegate: netTransport,
 				throttle: time.Now().Add(-(rateLimit)),
@@ -86,7 +132,9 @@ func formatURL(base string) (string, error) {
 
 func (c *Client) execute(req *http.Request) (resp *http.Response, err error) {
 	req.Header.Set(headerAccept, mediaTypeJSON)
-	req.Header.Set(headerContent, mediaTypeURLForm)
+	if req.Header.Get(headerContent) == "" {
+		req.Header.Set(headerContent, mediaTypeURLForm)
+	}
 
 	resp, err = c.client.Do(req)
 	return
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitSpacesRequests(t *testing.T) {
+	var mu sync.Mutex
+	sent := []time.Time{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, time.Now())
+	})
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequest(http.MethodGet, client.Endpoint, nil)
+			require.NoError(t, err)
+			resp, err := client.execute(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, sent, 3)
+	assert.GreaterOrEqual(t, sent[2].Sub(sent[0]), 2*rateLimit-10*time.Millisecond)
+}
+
+func TestRateLimitDoesNotWaitForSlowRequests(t *testing.T) {
+	received := make(chan struct{})
+	release := make(chan struct{})
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(received)
+			<-release
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		req, err := http.NewRequest(http.MethodGet, client.Endpoint+"/slow", nil)
+		require.NoError(t, err)
+		if resp, err := client.execute(req); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-received
+
+	// Another request is sent while the slow one is still in flight.
+	fast := make(chan error, 1)
+	go func() {
+		req, err := http.NewRequest(http.MethodGet, client.Endpoint+"/fast", nil)
+		if err != nil {
+			fast <- err
+			return
+		}
+		resp, err := client.execute(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		fast <- err
+	}()
+
+	select {
+	case err := <-fast:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Error("The request waited for the slow request to complete.")
+	}
+
+	close(release)
+	<-done
+}
+
+func TestRateLimitWaitIsCancelled(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	transport := client.client.Transport.(*rateLimitedTransport)
+	transport.throttle = time.Now().Add(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, client.Endpoint, nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = client.execute(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrDomainNotFound is returned when the domain itself does not exist, as
+// opposed to some of its annotations.
+var ErrDomainNotFound = errors.New("domain not found")
+
+// Whether the body of a 404 response says that the domain itself does not
+// exist, as {"err": "domain not found"}.
+func isDomainNotFound(body []byte) bool {
+	errResp := struct {
+		Err string `json:"err"`
+	}{}
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(errResp.Err), ErrDomainNotFound.Error())
+}
+
+// Reads a single domain with GET /domains/{domain}. The server responds with
+// the domain in the same envelope as GET /domains, holding a single object
+// instead of an array:
+//
+//	{"dt": {"domain": "example.com", "metadata": {"labels": {...}, "annotations": {...}}}}
+//
+// Returns ErrDomainNotFound if the server responds with 404.
+func (c *Client) GetDomain(ctx context.Context, domain string) (resp *Domain, err error) {
+	path, err := url.JoinPath(c.Endpoint, "domains", domain)
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var httpResp *http.Response
+	if httpResp, err = c.execute(req); err != nil {
+		return nil, err
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		if httpResp.StatusCode == http.StatusNotFound {
+			httpResp.Body.Close()
+			return nil, ErrDomainNotFound
+		}
+
+		return nil, handleErrorResponse(httpResp)
+	}
+
+	defer httpResp.Body.Close()
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	commonResp := SingleDomainResponse{}
+	if err = json.Unmarshal(body, &commonResp); err != nil {
+		return nil, err
+	}
+
+	// Anything else than the envelope above would read as a domain without
+	// labels or annotations.
+	if !strings.EqualFold(commonResp.Domain.Domain, domain) {
+		return nil, fmt.Errorf("unexpected response for domain %s: %s", domain, string(body))
+	}
+
+	return &commonResp.Domain, nil
+}
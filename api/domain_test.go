@@ -0,0 +1,64 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+	return client
+}
+
+func TestGetDomain(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/domains/example.com", r.URL.Path)
+		w.Write([]byte(`{"dt": {
+			"domain": "example.com",
+			"metadata": {
+				"labels": {"common/env": "prod"},
+				"annotations": {"common/status": "live"}
+			}
+		}}`))
+	})
+
+	domain, err := client.GetDomain(context.Background(), "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", domain.Domain)
+	assert.Equal(t, map[string]interface{}{"common/env": "prod"}, domain.Metadata.Labels)
+	assert.Equal(t, map[string]interface{}{"common/status": "live"}, domain.Metadata.Annotations)
+}
+
+func TestGetDomainNotFound(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"err":"not found"}`, http.StatusNotFound)
+	})
+
+	_, err := client.GetDomain(context.Background(), "example.com")
+	assert.ErrorIs(t, err, ErrDomainNotFound)
+}
+
+func TestGetDomainUnexpectedEnvelope(t *testing.T) {
+	for _, body := range []string{
+		`{"domain": "example.com", "metadata": {"labels": {"common/env": "prod"}}}`,
+		`{"dt": [{"domain": "example.com"}]}`,
+		`{"dt": {"domain": "other.com"}}`,
+	} {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		})
+
+		_, err := client.GetDomain(context.Background(), "example.com")
+		assert.Error(t, err, body)
+	}
+}
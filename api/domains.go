@@ -1,13 +1,14 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
 	"net/url"
 )
 
-func (c *Client) GetDomains(request DomainReq) (resp []*Domain, err error) {
+func (c *Client) GetDomains(ctx context.Context, request DomainReq) (resp []*Domain, err error) {
 	path, err := url.JoinPath(c.Endpoint, "domains")
 	if err != nil {
 		return nil, err
@@ -24,7 +25,7 @@ func (c *Client) GetDomains(request DomainReq) (resp []*Domain, err error) {
 	}
 	url.RawQuery = query.Encode()
 
-	req, err := http.NewRequest(http.MethodGet, url.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
 	if err != nil {
 		return nil, err
 	}
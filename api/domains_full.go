@@ -1,13 +1,14 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
 	"net/url"
 )
 
-func (c *Client) GetDomainsFull(request DomainReq) (resp []*DomainFull, err error) {
+func (c *Client) GetDomainsFull(ctx context.Context, request DomainReq) (resp []*DomainFull, err error) {
 	path, err := url.JoinPath(c.Endpoint, "domains", "full")
 	if err != nil {
 		return nil, err
@@ -24,7 +25,7 @@ func (c *Client) GetDomainsFull(request DomainReq) (resp []*DomainFull, err erro
 	}
 	url.RawQuery = query.Encode()
 
-	req, err := http.NewRequest(http.MethodGet, url.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -68,6 +68,10 @@ type DomainResponse struct {
 	Domains []*Domain `json:"dt"`
 }
 
+type SingleDomainResponse struct {
+	Domain Domain `json:"dt"`
+}
+
 type DomainFullResponse struct {
 	DomainsFull []*DomainFull `json:"dt"`
 }
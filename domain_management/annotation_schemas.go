@@ -0,0 +1,33 @@
+package domain_management
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/myklst/terraform-provider-st-domain-management/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// Validates the value of every root key against the provider annotation
+// schemas. Every violation is reported against the given attribute.
+func checkAnnotationSchemas(schemas *utils.AnnotationSchemas, attribute path.Path, annotations map[string]interface{}) (diags diag.Diagnostics) {
+	for _, key := range slices.Sorted(maps.Keys(annotations)) {
+		violations, err := schemas.Validate(key, annotations[key])
+		if err != nil {
+			diags.AddAttributeError(attribute, fmt.Sprintf("Unable to validate annotation %q", key), err.Error())
+			continue
+		}
+
+		for _, violation := range violations {
+			diags.AddAttributeError(
+				attribute,
+				fmt.Sprintf("Annotation %q does not match the schema for %q", key, violation.Pattern),
+				fmt.Sprintf("Offending value at %s: %s", violation.Path, violation.Message),
+			)
+		}
+	}
+	return diags
+}
@@ -0,0 +1,199 @@
+package domain_management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/myklst/terraform-provider-st-domain-management/api"
+)
+
+// How long reverting the applied steps of a failed transaction may take.
+const rollbackTimeout = time.Minute
+
+// annotationsTransaction applies the steps of an annotations update one API
+// call at a time, remembering how to revert each of them. Current always
+// holds the annotations the backend is known to hold, so that state can be
+// written accurately even when a step or a rollback fails.
+//
+// Every call is sent with If-Match on the revision returned by the previous
+// one, starting from the revision the keys of the resource were last checked
+// at, so the transaction fails instead of overwriting changes made outside
+// Terraform in between.
+type annotationsTransaction struct {
+	ctx      context.Context
+	client   *api.Client
+	domain   string
+	Current  map[string]interface{}
+	Revision string
+	undo     []func() error
+}
+
+func newAnnotationsTransaction(ctx context.Context, client *api.Client, domain string, current map[string]interface{}, revision string) *annotationsTransaction {
+	return &annotationsTransaction{
+		ctx:      ctx,
+		client:   client,
+		domain:   domain,
+		Current:  maps.Clone(current),
+		Revision: revision,
+	}
+}
+
+// Creates new root keys. Reverted by deleting them again.
+func (t *annotationsTransaction) Create(values map[string]interface{}) error {
+	if err := t.create(values); err != nil {
+		return err
+	}
+
+	keys := slices.Collect(maps.Keys(values))
+	t.undo = append(t.undo, func() error {
+		return t.delete(keys)
+	})
+	return nil
+}
+
+// Deletes root keys. Reverted by creating them with their previous values.
+func (t *annotationsTransaction) Delete(keys []string) error {
+	previous := map[string]interface{}{}
+	for _, k := range keys {
+		previous[k] = t.Current[k]
+	}
+
+	if err := t.delete(keys); err != nil {
+		return err
+	}
+
+	t.undo = append(t.undo, func() error {
+		return t.create(previous)
+	})
+	return nil
+}
+
+// Replaces the values of existing root keys. Reverted by writing back their
+// previous values.
+func (t *annotationsTransaction) Update(values map[string]interface{}) error {
+	previous := map[string]interface{}{}
+	for k := range values {
+		previous[k] = t.Current[k]
+	}
+
+	if err := t.update(values); err != nil {
+		return err
+	}
+
+	t.undo = append(t.undo, func() error {
+		return t.update(previous)
+	})
+	return nil
+}
+
+// Reverts every applied step, most recent first. Stops at the first step
+// that cannot be reverted.
+//
+// The steps are reverted even if the operation has timed out or was
+// cancelled, within a bounded time of their own.
+func (t *annotationsTransaction) Rollback() error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(t.ctx), rollbackTimeout)
+	defer cancel()
+
+	operationCtx := t.ctx
+	t.ctx = ctx
+	defer func() { t.ctx = operationCtx }()
+
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		if err := t.undo[i](); err != nil {
+			return err
+		}
+		t.undo = t.undo[:i]
+	}
+	return nil
+}
+
+func (t *annotationsTransaction) create(values map[string]interface{}) error {
+	payload, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	httpResp, revision, err := t.client.CreateAnnotations(t.ctx, t.domain, string(payload), t.Revision)
+	if err != nil {
+		return apiError(fmt.Sprintf("unable to create %v", slices.Sorted(maps.Keys(values))), httpResp, err)
+	}
+
+	maps.Copy(t.Current, values)
+	t.Revision = revision
+	return nil
+}
+
+func (t *annotationsTransaction) delete(keys []string) error {
+	payload, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+
+	httpResp, revision, err := t.client.DeleteAnnotations(t.ctx, t.domain, payload, t.Revision)
+	if err != nil {
+		return apiError(fmt.Sprintf("unable to delete %v", slices.Sorted(slices.Values(keys))), httpResp, err)
+	}
+
+	for _, k := range keys {
+		delete(t.Current, k)
+	}
+	t.Revision = revision
+	return nil
+}
+
+func (t *annotationsTransaction) update(values map[string]interface{}) error {
+	payload, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	httpResp, revision, err := t.client.UpdateAnnotations(t.ctx, t.domain, payload, t.Revision)
+	if err != nil {
+		return apiError(fmt.Sprintf("unable to update %v", slices.Sorted(maps.Keys(values))), httpResp, err)
+	}
+
+	maps.Copy(t.Current, values)
+	t.Revision = revision
+	return nil
+}
+
+// Returns an error if any of the root keys in state no longer holds its value
+// on the backend, i.e. was changed outside Terraform since it was last read.
+// Changes to root keys of others do not matter, so that resources sharing a
+// domain can be applied together.
+func checkUnchanged(stateObj, existing map[string]interface{}) error {
+	changed := []string{}
+	for _, k := range slices.Sorted(maps.Keys(stateObj)) {
+		if value, found := existing[k]; !found || !reflect.DeepEqual(value, stateObj[k]) {
+			changed = append(changed, k)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"root keys %v were changed outside Terraform since they were last read. "+
+			"Run terraform plan again to review the changes before applying",
+		changed,
+	)
+}
+
+// Combines the status code returned by the client with the response body.
+func apiError(action string, httpResp []byte, err error) error {
+	if errors.Is(err, api.ErrPreconditionFailed) {
+		return fmt.Errorf(
+			"%s: the annotations were changed outside Terraform since they were last read. "+
+				"Run terraform plan again to review the changes before applying: %w",
+			action, err,
+		)
+	}
+	return fmt.Errorf("%s, got error %w: %s", action, err, string(httpResp))
+}
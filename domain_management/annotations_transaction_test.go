@@ -0,0 +1,211 @@
+package domain_management
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/myklst/terraform-provider-st-domain-management/api"
+	"github.com/myklst/terraform-provider-st-domain-management/domain_management/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func initialAnnotations() map[string]interface{} {
+	return map[string]interface{}{
+		"common/a": "old",
+		"common/b": map[string]interface{}{"nested": true},
+	}
+}
+
+// Applies the steps of an update the way the resource does: create, delete,
+// update, then the ownership records.
+func applyUpdateSteps(tx *annotationsTransaction) error {
+	if err := tx.Create(map[string]interface{}{"common/c": float64(3)}); err != nil {
+		return err
+	}
+	if err := tx.Delete([]string{"common/b"}); err != nil {
+		return err
+	}
+	if err := tx.Update(map[string]interface{}{"common/a": "new"}); err != nil {
+		return err
+	}
+	return tx.SetOwners(nil, internal.Owners{"common/a": "module-a", "common/c": "module-a"}, false)
+}
+
+// Asserts that every root key the transaction touched holds on the backend
+// what the transaction reports.
+func assertMatchesBackend(t *testing.T, b *fakeBackend, tx *annotationsTransaction) {
+	t.Helper()
+
+	backend := b.snapshot()
+	tracked := tx.Annotations()
+	for _, k := range []string{"common/a", "common/b", "common/c"} {
+		value, found := backend[k]
+		trackedValue, trackedFound := tracked[k]
+		assert.Equal(t, found, trackedFound, k)
+		assert.Equal(t, value, trackedValue, k)
+	}
+	assert.Equal(t, strconv.Itoa(b.revision), tx.Revision)
+}
+
+func TestAnnotationsTransactionCommit(t *testing.T) {
+	b, client := newFakeBackend(t, initialAnnotations())
+	tx := newAnnotationsTransaction(context.Background(), client, testDomain, initialAnnotations(), "1")
+
+	require.NoError(t, applyUpdateSteps(tx))
+	assert.Equal(t, map[string]interface{}{"common/a": "new", "common/c": float64(3)}, b.snapshot())
+	assertMatchesBackend(t, b, tx)
+	assert.Equal(t, []string{
+		"POST common/c",
+		"DELETE common/b",
+		"PATCH common/a",
+		"POST " + internal.OwnersAnnotationKey,
+	}, b.writes)
+}
+
+func TestAnnotationsTransactionRollback(t *testing.T) {
+	steps := []string{
+		"POST common/c",
+		"DELETE common/b",
+		"PATCH common/a",
+		"POST " + internal.OwnersAnnotationKey,
+	}
+	undo := []string{
+		"DELETE common/c",
+		"POST common/b",
+		"PATCH common/a",
+	}
+
+	for failing := 1; failing <= len(steps); failing++ {
+		t.Run(steps[failing-1], func(t *testing.T) {
+			b, client := newFakeBackend(t, initialAnnotations())
+			b.fail[failing] = http.StatusInternalServerError
+			tx := newAnnotationsTransaction(context.Background(), client, testDomain, initialAnnotations(), "1")
+
+			require.Error(t, applyUpdateSteps(tx))
+			require.NoError(t, tx.Rollback())
+
+			// The applied steps are reverted most recent first.
+			expected := slices.Clone(steps[:failing])
+			for i := failing - 2; i >= 0; i-- {
+				expected = append(expected, undo[i])
+			}
+			assert.Equal(t, expected, b.writes)
+			assert.Equal(t, initialAnnotations(), b.snapshot())
+			assertMatchesBackend(t, b, tx)
+		})
+	}
+}
+
+func TestAnnotationsTransactionPreconditionFailed(t *testing.T) {
+	b, client := newFakeBackend(t, initialAnnotations())
+	// Another writer changes the domain between the second and third step.
+	b.outside[2] = func(annotations map[string]interface{}) {
+		annotations["team-b/status"] = "changed"
+	}
+	tx := newAnnotationsTransaction(context.Background(), client, testDomain, initialAnnotations(), "1")
+
+	err := applyUpdateSteps(tx)
+	require.ErrorIs(t, err, api.ErrPreconditionFailed)
+
+	// Reverting is refused as well, since the revision is stale. The
+	// transaction still reports what the backend holds.
+	require.ErrorIs(t, tx.Rollback(), api.ErrPreconditionFailed)
+	assert.Equal(t, []string{
+		"POST common/c",
+		"DELETE common/b",
+		"PATCH common/a",
+		"POST common/b",
+	}, b.writes)
+	assert.Equal(t, map[string]interface{}{
+		"common/a":      "old",
+		"common/c":      float64(3),
+		"team-b/status": "changed",
+	}, b.snapshot())
+
+	backend := b.snapshot()
+	tracked := tx.Annotations()
+	for _, k := range []string{"common/a", "common/b", "common/c"} {
+		assert.Equal(t, backend[k], tracked[k], k)
+	}
+}
+
+func TestAnnotationsTransactionRollbackFails(t *testing.T) {
+	b, client := newFakeBackend(t, initialAnnotations())
+	// The update fails, and so does reverting the creation after the
+	// deleted key has been restored.
+	b.fail[3] = http.StatusInternalServerError
+	b.fail[5] = http.StatusInternalServerError
+	tx := newAnnotationsTransaction(context.Background(), client, testDomain, initialAnnotations(), "1")
+
+	require.Error(t, applyUpdateSteps(tx))
+	require.Error(t, tx.Rollback())
+	assert.Equal(t, []string{
+		"POST common/c",
+		"DELETE common/b",
+		"PATCH common/a",
+		"POST common/b",
+		"DELETE common/c",
+	}, b.writes)
+	assert.Equal(t, map[string]interface{}{
+		"common/a": "old",
+		"common/b": map[string]interface{}{"nested": true},
+		"common/c": float64(3),
+	}, b.snapshot())
+	assertMatchesBackend(t, b, tx)
+}
+
+// Returns a state of the resource with every attribute null.
+func nullResourceState(t *testing.T, r *domainAnnotationsResource) tfsdk.State {
+	ctx := context.Background()
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	require.False(t, schemaResp.Diagnostics.HasError())
+
+	objType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	attrs := map[string]tftypes.Value{}
+	for k, attrType := range objType.AttributeTypes {
+		attrs[k] = tftypes.NewValue(attrType, nil)
+	}
+	return tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objType, attrs)}
+}
+
+func TestRollbackUpdateHidesWriteOnlyKeys(t *testing.T) {
+	ctx := context.Background()
+	b, client := newFakeBackend(t, initialAnnotations())
+	r := &domainAnnotationsResource{client: client}
+
+	var state domainAnnotationResourceModel
+	emptyState := nullResourceState(t, r)
+	require.False(t, emptyState.Get(ctx, &state).HasError())
+	require.False(t, state.setAllAnnotations(initialAnnotations()).HasError())
+
+	// The write-only key is created, then the update of a plain key fails,
+	// and so does reverting the creation.
+	b.fail[2] = http.StatusInternalServerError
+	b.fail[3] = http.StatusInternalServerError
+	tx := newAnnotationsTransaction(ctx, client, testDomain, initialAnnotations(), "1")
+	require.NoError(t, tx.Create(map[string]interface{}{"secret/token": "hunter2"}))
+	err := tx.Update(map[string]interface{}{"common/a": "new"})
+	require.Error(t, err)
+
+	resp := &resource.UpdateResponse{State: emptyState}
+	r.rollbackUpdate(ctx, tx, &state, []string{"secret/token"}, resp, "Update Annotation: Update Key Error", err)
+
+	require.True(t, resp.Diagnostics.HasError())
+	assert.Equal(t, "hunter2", b.snapshot()["secret/token"])
+
+	var got domainAnnotationResourceModel
+	require.False(t, resp.State.Get(ctx, &got).HasError())
+	annotations := map[string]interface{}{}
+	require.False(t, got.Annotations.Unmarshal(&annotations).HasError())
+	assert.Equal(t, initialAnnotations(), annotations)
+	assert.Equal(t, strconv.Itoa(b.revision), got.Revision.ValueString())
+}
@@ -0,0 +1,273 @@
+package domain_management
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/myklst/terraform-provider-st-domain-management/api"
+	"github.com/myklst/terraform-provider-st-domain-management/domain_management/internal"
+	"github.com/myklst/terraform-provider-st-domain-management/utils"
+	"github.com/stretchr/testify/require"
+	"gomodules.xyz/jsonpatch/v2"
+)
+
+const testDomain = "example.com"
+
+// fakeBackend serves the endpoints of a single domain. Writes are numbered
+// from 1 in the order they arrive, and can be made to fail.
+type fakeBackend struct {
+	mu          sync.Mutex
+	annotations map[string]interface{}
+	labels      map[string]interface{}
+	revision    int
+	// Set once the domain itself has been deleted.
+	missing bool
+	// Whether JSON Patch requests are applied, instead of being refused with
+	// 415.
+	jsonPatch bool
+
+	// Every read request as "<method> <path>".
+	reads []string
+	// Every write request as "<method> <root keys>", including failed ones.
+	writes []string
+	// Every dry-run write request, logged like writes.
+	dryRuns []string
+	// Whether dry-run writes are rejected, with a body echoing the request
+	// like some servers do.
+	rejectDryRuns bool
+	// The status to fail a write with, by its number.
+	fail map[int]int
+	// Changes made outside Terraform right after a successful write, by its
+	// number.
+	outside map[int]func(annotations map[string]interface{})
+}
+
+func newFakeBackend(t *testing.T, annotations map[string]interface{}) (*fakeBackend, *api.Client) {
+	b, server := newFakeServer(t, annotations)
+
+	client, err := api.NewClient(server.URL)
+	require.NoError(t, err)
+	return b, client
+}
+
+func newFakeServer(t *testing.T, annotations map[string]interface{}) (*fakeBackend, *httptest.Server) {
+	b := &fakeBackend{
+		annotations: annotations,
+		labels:      map[string]interface{}{"common/env": "prod"},
+		revision:    1,
+		fail:        map[int]int{},
+		outside:     map[int]func(map[string]interface{}){},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(b.serveHTTP))
+	t.Cleanup(server.Close)
+	return b, server
+}
+
+func (b *fakeBackend) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/domains":
+		b.reads = append(b.reads, "GET "+r.URL.Path)
+		b.serveDomains(w)
+	case r.Method == http.MethodGet && r.URL.Path == "/domains/"+testDomain:
+		b.reads = append(b.reads, "GET "+r.URL.Path)
+		b.serveDomain(w)
+	case r.Method == http.MethodGet && r.URL.Path == "/domains/"+testDomain+"/annotations":
+		b.reads = append(b.reads, "GET "+r.URL.Path)
+		b.serveAnnotations(w, r)
+	case r.URL.Path == "/domains/"+testDomain+"/annotations":
+		b.serveWrite(w, r)
+	default:
+		http.Error(w, `{"err":"not found"}`, http.StatusNotFound)
+	}
+}
+
+func (b *fakeBackend) domain() *api.Domain {
+	return &api.Domain{
+		Domain: testDomain,
+		Metadata: api.Metadata{
+			Labels:      b.labels,
+			Annotations: b.annotations,
+		},
+	}
+}
+
+func (b *fakeBackend) serveDomains(w http.ResponseWriter) {
+	domains := []*api.Domain{}
+	if !b.missing {
+		domains = append(domains, b.domain())
+	}
+	json.NewEncoder(w).Encode(api.DomainResponse{Domains: domains})
+}
+
+func (b *fakeBackend) serveDomain(w http.ResponseWriter) {
+	if b.missing {
+		http.Error(w, `{"err":"domain not found"}`, http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(api.SingleDomainResponse{Domain: *b.domain()})
+}
+
+func (b *fakeBackend) serveAnnotations(w http.ResponseWriter, r *http.Request) {
+	if b.missing {
+		http.Error(w, `{"err":"domain not found"}`, http.StatusNotFound)
+		return
+	}
+
+	var keys []string
+	if err := json.Unmarshal([]byte(r.URL.Query().Get("filter")), &keys); err != nil {
+		http.Error(w, `{"err":"invalid filter"}`, http.StatusBadRequest)
+		return
+	}
+
+	annotations := map[string]interface{}{}
+	for _, k := range keys {
+		if value, found := b.annotations[k]; found {
+			annotations[k] = value
+		}
+	}
+	if len(annotations) == 0 {
+		http.Error(w, `{"err":"annotations not found"}`, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("ETag", strconv.Itoa(b.revision))
+	json.NewEncoder(w).Encode(api.AnnotationsResponse{Domain: api.Domain{
+		Domain:   testDomain,
+		Metadata: api.Metadata{Annotations: annotations},
+	}})
+}
+
+func (b *fakeBackend) serveWrite(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Content-Type") == "application/json-patch+json" {
+		b.serveJSONPatch(w, r)
+		return
+	}
+
+	var keys []string
+	values := map[string]interface{}{}
+	switch r.Method {
+	case http.MethodPost, http.MethodPatch:
+		if err := json.NewDecoder(r.Body).Decode(&values); err != nil {
+			http.Error(w, `{"err":"invalid body"}`, http.StatusBadRequest)
+			return
+		}
+		keys = slices.Sorted(maps.Keys(values))
+	case http.MethodDelete:
+		if err := json.Unmarshal([]byte(r.URL.Query().Get("filter")), &keys); err != nil {
+			http.Error(w, `{"err":"invalid filter"}`, http.StatusBadRequest)
+			return
+		}
+		slices.Sort(keys)
+	default:
+		http.Error(w, `{"err":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.URL.Query().Get("dryRun") == "true" {
+		b.dryRuns = append(b.dryRuns, fmt.Sprintf("%s %s", r.Method, strings.Join(keys, ",")))
+		if b.rejectDryRuns {
+			body, _ := json.Marshal(map[string]interface{}{"err": "invalid annotations", "request": values})
+			http.Error(w, string(body), http.StatusUnprocessableEntity)
+		}
+		return
+	}
+
+	b.writes = append(b.writes, fmt.Sprintf("%s %s", r.Method, strings.Join(keys, ",")))
+	n := len(b.writes)
+
+	if status, found := b.fail[n]; found {
+		http.Error(w, `{"err":"injected failure"}`, status)
+		return
+	}
+	if b.missing {
+		http.Error(w, `{"err":"domain not found"}`, http.StatusNotFound)
+		return
+	}
+	if revision := r.Header.Get("If-Match"); revision != "" && revision != strconv.Itoa(b.revision) {
+		http.Error(w, `{"err":"revision mismatch"}`, http.StatusPreconditionFailed)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		for _, k := range keys {
+			delete(b.annotations, k)
+		}
+	} else {
+		maps.Copy(b.annotations, values)
+	}
+	b.revision++
+	w.Header().Set("ETag", strconv.Itoa(b.revision))
+	w.WriteHeader(http.StatusOK)
+
+	if change, found := b.outside[n]; found {
+		change(b.annotations)
+		b.revision++
+	}
+}
+
+// Applies the add, replace and remove operations of a JSON Patch. Logged as
+// "JSON-PATCH <root keys>".
+func (b *fakeBackend) serveJSONPatch(w http.ResponseWriter, r *http.Request) {
+	if !b.jsonPatch {
+		http.Error(w, `{"err":"unsupported media type"}`, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var patch []jsonpatch.Operation
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		http.Error(w, `{"err":"invalid body"}`, http.StatusBadRequest)
+		return
+	}
+
+	keys := []string{}
+	for _, operation := range patch {
+		tokens, err := utils.ParseJSONPointer(operation.Path)
+		if err != nil || len(tokens) == 0 {
+			http.Error(w, `{"err":"invalid path"}`, http.StatusBadRequest)
+			return
+		}
+		keys = append(keys, tokens[0])
+	}
+	slices.Sort(keys)
+	b.writes = append(b.writes, "JSON-PATCH "+strings.Join(slices.Compact(keys), ","))
+
+	if revision := r.Header.Get("If-Match"); revision != "" && revision != strconv.Itoa(b.revision) {
+		http.Error(w, `{"err":"revision mismatch"}`, http.StatusPreconditionFailed)
+		return
+	}
+
+	for _, operation := range patch {
+		tokens, _ := utils.ParseJSONPointer(operation.Path)
+		if operation.Operation == "remove" {
+			utils.RemoveJSONPointer(b.annotations, tokens)
+		} else if err := utils.SetJSONPointer(b.annotations, tokens, operation.Value); err != nil {
+			http.Error(w, `{"err":"invalid path"}`, http.StatusConflict)
+			return
+		}
+	}
+	b.revision++
+	w.Header().Set("ETag", strconv.Itoa(b.revision))
+	w.WriteHeader(http.StatusOK)
+}
+
+// Returns the root keys held by the backend, without the ownership records.
+func (b *fakeBackend) snapshot() map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	annotations := maps.Clone(b.annotations)
+	delete(annotations, internal.OwnersAnnotationKey)
+	return annotations
+}
@@ -2,11 +2,28 @@ package domain_management
 
 import (
 	"fmt"
+
 	"github.com/myklst/terraform-provider-st-domain-management/api"
+	"github.com/myklst/terraform-provider-st-domain-management/utils"
 )
 
 type Config struct {
-	Endpoint string
+	Endpoint          string
+	KeyPolicy         *utils.KeyPolicy
+	AnnotationSchemas *utils.AnnotationSchemas
+	Policy            *utils.Policy
+	ValidateOnPlan    bool
+	JSONPatch         bool
+}
+
+// ProviderData is handed to every resource and data source once the provider
+// has been configured.
+type ProviderData struct {
+	Client            *api.Client
+	KeyPolicy         *utils.KeyPolicy
+	AnnotationSchemas *utils.AnnotationSchemas
+	Policy            *utils.Policy
+	ValidateOnPlan    bool
 }
 
 func (c *Config) Client() (*api.Client, error) {
@@ -15,6 +32,22 @@ func (c *Config) Client() (*api.Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error setting up client: %s", err)
 	}
+	client.JSONPatch = c.JSONPatch
 
 	return client, nil
 }
+
+func (c *Config) ProviderData() (*ProviderData, error) {
+	client, err := c.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProviderData{
+		Client:            client,
+		KeyPolicy:         c.KeyPolicy,
+		AnnotationSchemas: c.AnnotationSchemas,
+		Policy:            c.Policy,
+		ValidateOnPlan:    c.ValidateOnPlan,
+	}, nil
+}
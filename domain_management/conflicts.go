@@ -0,0 +1,111 @@
+package domain_management
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// Compares the planned root keys against the backend, so that conflicts that
+// would make apply fail are reported by plan instead:
+//   - Root keys to be created that already exist. On create these are
+//     resolved according to on_conflict, on update they always fail.
+//   - Root keys to be updated that no longer exist.
+//
+// Returns the current values of the planned root keys on the backend.
+func (r *domainAnnotationsResource) checkConflicts(ctx context.Context, domain string, onConflict string, stateObj, planObj map[string]interface{}, sensitive sensitiveKeys, creating bool) (existing map[string]interface{}, diags diag.Diagnostics) {
+	created, updated, _ := plannedChanges(stateObj, planObj)
+	if len(created) == 0 && len(updated) == 0 {
+		return nil, diags
+	}
+
+	payload, err := json.Marshal(append(created, updated...))
+	if err != nil {
+		diags.AddError("JSON Marshal Error", err.Error())
+		return nil, diags
+	}
+
+	existing, _, err = r.client.ReadAnnotations(ctx, domain, payload)
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read annotations of %s to detect conflicts, got error: %s", domain, err))
+		return nil, diags
+	}
+
+	for _, k := range created {
+		existingValue, found := existing[k]
+		switch {
+		case !found:
+		case !creating:
+			diags.AddAttributeError(
+				sensitive.path(k),
+				fmt.Sprintf("Annotation key %q already exists", k),
+				"The key was created outside Terraform. on_conflict only applies when the resource is created, import the key instead.",
+			)
+		case onConflict == onConflictOverwrite:
+			diags.AddAttributeWarning(
+				sensitive.path(k),
+				fmt.Sprintf("Annotation key %q already exists and will be overwritten", k),
+				fmt.Sprintf("Existing value: %s", sensitive.format(k, existingValue)),
+			)
+		case onConflict == onConflictAdoptIfEqual && reflect.DeepEqual(existingValue, planObj[k]):
+		case onConflict == onConflictAdoptIfEqual:
+			diags.AddAttributeError(
+				sensitive.path(k),
+				fmt.Sprintf("Annotation key %q already exists with a different value", k),
+				fmt.Sprintf("Existing value: %s", sensitive.format(k, existingValue)),
+			)
+		default:
+			diags.AddAttributeError(
+				sensitive.path(k),
+				fmt.Sprintf("Annotation key %q already exists", k),
+				"Set on_conflict to \"adopt_if_equal\" or \"overwrite\" to manage the existing key, or import it.",
+			)
+		}
+	}
+
+	for _, k := range updated {
+		if _, found := existing[k]; !found {
+			diags.AddAttributeError(
+				sensitive.path(k),
+				fmt.Sprintf("Annotation key %q no longer exists", k),
+				"The key was deleted outside Terraform. Run terraform refresh, or plan without -refresh=false, to recreate it.",
+			)
+		}
+	}
+	return existing, diags
+}
+
+// Returns the sorted root keys the plan creates, updates and deletes.
+func plannedChanges(stateObj, planObj map[string]interface{}) (created, updated, deleted []string) {
+	for k, v := range planObj {
+		previous, found := stateObj[k]
+		switch {
+		case !found:
+			created = append(created, k)
+		case !reflect.DeepEqual(previous, v):
+			updated = append(updated, k)
+		}
+	}
+	for k := range stateObj {
+		if _, found := planObj[k]; !found {
+			deleted = append(deleted, k)
+		}
+	}
+	slices.Sort(created)
+	slices.Sort(updated)
+	slices.Sort(deleted)
+	return created, updated, deleted
+}
+
+// Formats a JSON value for diagnostics.
+func formatJSON(v interface{}) string {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(bytes)
+}
@@ -10,8 +10,10 @@ import (
 	"github.com/myklst/terraform-provider-st-domain-management/domain_management/internal"
 	"github.com/myklst/terraform-provider-st-domain-management/utils"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 )
 
 func NewDomainDataSource() datasource.DataSource {
@@ -19,7 +21,8 @@ func NewDomainDataSource() datasource.DataSource {
 }
 
 type domainFilterDataSource struct {
-	client *api.Client
+	client    *api.Client
+	keyPolicy *utils.KeyPolicy
 }
 
 func (d *domainFilterDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -62,6 +65,10 @@ func (d *domainFilterDataSource) Schema(ctx context.Context, req datasource.Sche
 				Required:       false,
 				Optional:       true,
 			},
+			"output_typing": internal.OutputTypingAttribute,
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx),
 		},
 	}
 }
@@ -72,18 +79,19 @@ func (d *domainFilterDataSource) Configure(ctx context.Context, req datasource.C
 		return
 	}
 
-	client, ok := req.ProviderData.(*api.Client)
+	providerData, ok := req.ProviderData.(*ProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *api.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	d.client = client
+	d.client = providerData.Client
+	d.keyPolicy = providerData.KeyPolicy
 }
 
 func (d *domainFilterDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
@@ -95,13 +103,33 @@ func (d *domainFilterDataSource) Read(ctx context.Context, req datasource.ReadRe
 		return
 	}
 
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	if state.DomainAnnotations != nil {
+		keys, err := state.DomainAnnotations.Keys()
+		if err != nil {
+			resp.Diagnostics.AddError("JSON Error", fmt.Sprintf("Cannot convert filter input to json: %s", err))
+			return
+		}
+		resp.Diagnostics.Append(checkKeyPolicy(d.keyPolicy, path.Root("domain_annotations"), keys)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	payload, err := state.Payload()
 	if err != nil {
 		resp.Diagnostics.AddError("JSON Error", fmt.Sprintf("Cannot convert filter input to json: %s", err))
 		return
 	}
 
-	domains, err := d.client.GetDomains(payload)
+	domains, err := d.client.GetDomains(ctx, payload)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read domains: %s", err))
 		return
@@ -128,7 +156,7 @@ func (d *domainFilterDataSource) Read(ctx context.Context, req datasource.ReadRe
 		return
 	}
 
-	state.Domains, err = utils.JSONToTerraformDynamicValue(bytes)
+	state.Domains, err = utils.JSONToTerraformDynamicValueWithMode(bytes, internal.OutputTypingMode(state.OutputTyping))
 	if err != nil {
 		resp.Diagnostics.AddError(err.Error(), "")
 		return
@@ -10,9 +10,11 @@ import (
 	"github.com/myklst/terraform-provider-st-domain-management/domain_management/internal"
 	"github.com/myklst/terraform-provider-st-domain-management/utils"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 )
 
@@ -20,7 +22,9 @@ type subdomainFilterDataSourceModel struct {
 	DomainLabels      *internal.Filters      `tfsdk:"domain_labels" json:"domain_labels"`
 	DomainAnnotations *internal.Filters      `tfsdk:"domain_annotations" json:"domain_annotations"`
 	SubdomainLabels   *internal.Filters      `tfsdk:"subdomain_labels" json:"subdomains_labels"`
+	OutputTyping      basetypes.StringValue  `tfsdk:"output_typing" json:"output_typing"`
 	Domains           basetypes.DynamicValue `tfsdk:"domains" json:"domains"`
+	Timeouts          timeouts.Value         `tfsdk:"timeouts" json:"-"`
 }
 
 func NewSubdomainDataSource() datasource.DataSource {
@@ -28,7 +32,8 @@ func NewSubdomainDataSource() datasource.DataSource {
 }
 
 type subdomainFilterDataSource struct {
-	client *api.Client
+	client    *api.Client
+	keyPolicy *utils.KeyPolicy
 }
 
 func (d *subdomainFilterDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -81,6 +86,10 @@ func (d *subdomainFilterDataSource) Schema(ctx context.Context, req datasource.S
 				Required:       false,
 				Optional:       true,
 			},
+			"output_typing": internal.OutputTypingAttribute,
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx),
 		},
 	}
 }
@@ -91,18 +100,19 @@ func (d *subdomainFilterDataSource) Configure(ctx context.Context, req datasourc
 		return
 	}
 
-	client, ok := req.ProviderData.(*api.Client)
+	providerData, ok := req.ProviderData.(*ProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *api.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	d.client = client
+	d.client = providerData.Client
+	d.keyPolicy = providerData.KeyPolicy
 }
 
 func (d *subdomainFilterDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
@@ -114,6 +124,26 @@ func (d *subdomainFilterDataSource) Read(ctx context.Context, req datasource.Rea
 		return
 	}
 
+	readTimeout, diags := state.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	if state.DomainAnnotations != nil {
+		keys, err := state.DomainAnnotations.Keys()
+		if err != nil {
+			resp.Diagnostics.AddError("JSON Error", fmt.Sprintf("Cannot convert filter input to json: %s", err))
+			return
+		}
+		resp.Diagnostics.Append(checkKeyPolicy(d.keyPolicy, path.Root("domain_annotations"), keys)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	domainRequest := internal.FullDomainFilterDataSourceModel{
 		DomainLabels:      state.DomainLabels,
 		DomainAnnotations: state.DomainAnnotations,
@@ -126,7 +156,7 @@ func (d *subdomainFilterDataSource) Read(ctx context.Context, req datasource.Rea
 		return
 	}
 
-	domainsFull, err := d.client.GetDomainsFull(payload)
+	domainsFull, err := d.client.GetDomainsFull(ctx, payload)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read domains, got error: %s", err))
 		return
@@ -159,7 +189,7 @@ func (d *subdomainFilterDataSource) Read(ctx context.Context, req datasource.Rea
 		return
 	}
 
-	state.Domains, err = utils.JSONToTerraformDynamicValue(bytes)
+	state.Domains, err = utils.JSONToTerraformDynamicValueWithMode(bytes, internal.OutputTypingMode(state.OutputTyping))
 	if err != nil {
 		resp.Diagnostics.AddError(err.Error(), "")
 		return
@@ -0,0 +1,141 @@
+package domain_management
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// The write-only root keys a plan writes. Their values are only in the
+// configuration, and the root keys written by the last apply only in private
+// state.
+type plannedWriteOnly struct {
+	values   map[string]interface{}
+	previous []string
+}
+
+// Sends the planned writes to the server as dry-run requests, so that
+// server-side validation errors are reported by plan. Existing root keys
+// are handled as apply would handle them according to on_conflict. The
+// response body is left out of the diagnostics of requests holding sensitive
+// or write-only values, since the server may echo them.
+func (r *domainAnnotationsResource) dryRun(ctx context.Context, domain string, onConflict string, stateObj, planObj, existing map[string]interface{}, writeOnly plannedWriteOnly, sensitive sensitiveKeys, creating bool) (diags diag.Diagnostics) {
+	created, updated, deleted := plannedChanges(stateObj, planObj)
+
+	creationPayload := map[string]interface{}{}
+	updatePayload := map[string]interface{}{}
+	for _, k := range created {
+		if _, found := existing[k]; !found {
+			creationPayload[k] = planObj[k]
+		} else if creating && onConflict == onConflictOverwrite {
+			updatePayload[k] = planObj[k]
+		}
+	}
+	for _, k := range updated {
+		updatePayload[k] = planObj[k]
+	}
+
+	if creating && onConflict != onConflictError && len(writeOnly.values) > 0 {
+		// Whether the write-only root keys already exist was not read with
+		// the others.
+		payload, err := json.Marshal(slices.Sorted(maps.Keys(writeOnly.values)))
+		if err != nil {
+			diags.AddError("JSON Marshal Error", err.Error())
+			return diags
+		}
+		existingWriteOnly, _, err := r.client.ReadAnnotations(ctx, domain, payload)
+		if err != nil {
+			diags.AddError("Client Error", fmt.Sprintf("Unable to read write-only annotations of %s for the dry run, got error: %s", domain, err))
+			return diags
+		}
+		existing = maps.Clone(existing)
+		if existing == nil {
+			existing = map[string]interface{}{}
+		}
+		maps.Copy(existing, existingWriteOnly)
+	}
+	for k, v := range writeOnly.values {
+		_, found := existing[k]
+		switch {
+		case slices.Contains(writeOnly.previous, k):
+			updatePayload[k] = v
+		case !found:
+			creationPayload[k] = v
+		case creating && onConflict == onConflictOverwrite:
+			updatePayload[k] = v
+		}
+	}
+	for _, k := range writeOnly.previous {
+		if _, found := writeOnly.values[k]; !found {
+			deleted = append(deleted, k)
+		}
+	}
+
+	// Whether the payload holds values that must not show up in diagnostics.
+	hidesValues := func(payload map[string]interface{}) bool {
+		for k := range payload {
+			if sensitive.hides(k) {
+				return true
+			}
+		}
+		return false
+	}
+	// Reports the error on the attribute holding the root keys, if there
+	// is a single one.
+	addError := func(keys []string, detail string) {
+		if attribute, ok := sensitive.commonPath(keys); ok {
+			diags.AddAttributeError(attribute, "Server rejected the planned annotations", detail)
+		} else {
+			diags.AddError("Server rejected the planned annotations", detail)
+		}
+	}
+
+	if len(creationPayload) > 0 {
+		payload, err := json.Marshal(creationPayload)
+		if err != nil {
+			diags.AddError("JSON Marshal Error", err.Error())
+			return diags
+		}
+		if httpResp, err := r.client.DryRunCreateAnnotations(ctx, domain, string(payload)); err != nil {
+			addError(slices.Collect(maps.Keys(creationPayload)),
+				dryRunError("dry run of creating annotations failed", httpResp, err, hidesValues(creationPayload)).Error())
+		}
+	}
+
+	if len(updatePayload) > 0 {
+		payload, err := json.Marshal(updatePayload)
+		if err != nil {
+			diags.AddError("JSON Marshal Error", err.Error())
+			return diags
+		}
+		if httpResp, err := r.client.DryRunUpdateAnnotations(ctx, domain, payload); err != nil {
+			addError(slices.Collect(maps.Keys(updatePayload)),
+				dryRunError("dry run of updating annotations failed", httpResp, err, hidesValues(updatePayload)).Error())
+		}
+	}
+
+	if len(deleted) > 0 && !creating {
+		payload, err := json.Marshal(deleted)
+		if err != nil {
+			diags.AddError("JSON Marshal Error", err.Error())
+			return diags
+		}
+		if httpResp, err := r.client.DryRunDeleteAnnotations(ctx, domain, payload); err != nil {
+			addError(deleted, apiError("dry run of deleting annotations failed", httpResp, err).Error())
+		}
+	}
+	return diags
+}
+
+// Returns the error of a dry-run request, without the response body if the
+// request held sensitive values.
+func dryRunError(action string, httpResp []byte, err error, hidesValues bool) error {
+	if hidesValues {
+		return fmt.Errorf("%s, got error %w. The response is not shown, since the request held sensitive values", action, err)
+	}
+	return apiError(action, httpResp, err)
+}
@@ -0,0 +1,116 @@
+package domain_management
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validateOnPlanConfig() map[string]tftypes.Value {
+	return map[string]tftypes.Value{"validate_on_plan": tftypes.NewValue(tftypes.Bool, true)}
+}
+
+// Returns the error diagnostics with the summary.
+func errorsWithSummary(diags []*tfprotov6.Diagnostic, summary string) []*tfprotov6.Diagnostic {
+	found := []*tfprotov6.Diagnostic{}
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError && d.Summary == summary {
+			found = append(found, d)
+		}
+	}
+	return found
+}
+
+func TestDryRunIncludesWriteOnlyKeys(t *testing.T) {
+	p := newTestProvider(t, map[string]interface{}{}, validateOnPlanConfig())
+
+	config := annotationsConfig(p, `{"common/a": "a"}`, map[string]tftypes.Value{
+		"annotations_wo": stringValue(`{"secret/token": "hunter2"}`),
+	})
+	state, diags := p.apply(annotationsResourceType, nil, config)
+	requireNoErrors(t, diags)
+	assert.Equal(t, []string{"POST common/a,secret/token"}, p.backend.dryRuns)
+
+	// Unchanged write-only values are not written again.
+	state, diags = p.apply(annotationsResourceType, state, annotationsConfig(p, `{"common/a": "b"}`, map[string]tftypes.Value{
+		"annotations_wo": stringValue(`{"secret/token": "hunter2"}`),
+	}))
+	requireNoErrors(t, diags)
+	assert.Equal(t, []string{"POST common/a,secret/token", "PATCH common/a"}, p.backend.dryRuns)
+
+	// Bumping the version writes them.
+	_, diags = p.apply(annotationsResourceType, state, annotationsConfig(p, `{"common/a": "b"}`, map[string]tftypes.Value{
+		"annotations_wo":         stringValue(`{"secret/token": "hunter3"}`),
+		"annotations_wo_version": tftypes.NewValue(tftypes.Number, 1),
+	}))
+	requireNoErrors(t, diags)
+	assert.Equal(t, []string{"POST common/a,secret/token", "PATCH common/a", "PATCH secret/token"}, p.backend.dryRuns)
+}
+
+func TestDryRunHidesResponseOfSensitiveValues(t *testing.T) {
+	for name, attrs := range map[string]map[string]tftypes.Value{
+		"sensitive_annotations": {"sensitive_annotations": stringValue(`{"secret/key": "hunter2"}`)},
+		"annotations_wo":        {"annotations_wo": stringValue(`{"secret/key": "hunter2"}`)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := newTestProvider(t, map[string]interface{}{}, validateOnPlanConfig())
+			p.backend.rejectDryRuns = true
+
+			_, diags := p.apply(annotationsResourceType, nil, annotationsConfig(p, `{"common/a": "a"}`, attrs))
+			rejected := errorsWithSummary(diags, "Server rejected the planned annotations")
+			require.Len(t, rejected, 1)
+			assert.NotContains(t, rejected[0].Detail, "hunter2")
+			assert.Empty(t, p.backend.writes)
+		})
+	}
+
+	// Without sensitive values the response is shown.
+	p := newTestProvider(t, map[string]interface{}{}, validateOnPlanConfig())
+	p.backend.rejectDryRuns = true
+
+	_, diags := p.apply(annotationsResourceType, nil, annotationsConfig(p, `{"common/a": "a"}`, nil))
+	rejected := errorsWithSummary(diags, "Server rejected the planned annotations")
+	require.Len(t, rejected, 1)
+	assert.Contains(t, rejected[0].Detail, "invalid annotations")
+}
+
+func TestDryRunErrorAttribute(t *testing.T) {
+	for name, tc := range map[string]struct {
+		attrs     map[string]tftypes.Value
+		attribute *tftypes.AttributePath
+	}{
+		"annotations": {
+			attrs:     map[string]tftypes.Value{"annotations": stringValue(`{"common/a": "a"}`)},
+			attribute: tftypes.NewAttributePath().WithAttributeName("annotations"),
+		},
+		"sensitive_annotations": {
+			attrs:     map[string]tftypes.Value{"sensitive_annotations": stringValue(`{"secret/key": "hunter2"}`)},
+			attribute: tftypes.NewAttributePath().WithAttributeName("sensitive_annotations"),
+		},
+		"annotations_wo": {
+			attrs:     map[string]tftypes.Value{"annotations_wo": stringValue(`{"secret/key": "hunter2"}`)},
+			attribute: tftypes.NewAttributePath().WithAttributeName("annotations_wo"),
+		},
+		// Keys of several attributes are reported on none of them.
+		"mixed": {
+			attrs: map[string]tftypes.Value{
+				"annotations":           stringValue(`{"common/a": "a"}`),
+				"sensitive_annotations": stringValue(`{"secret/key": "hunter2"}`),
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := newTestProvider(t, map[string]interface{}{}, validateOnPlanConfig())
+			p.backend.rejectDryRuns = true
+
+			tc.attrs["domain"] = stringValue(testDomain)
+			_, diags := p.apply(annotationsResourceType, nil, p.resourceConfig(annotationsResourceType, tc.attrs))
+			rejected := errorsWithSummary(diags, "Server rejected the planned annotations")
+			require.Len(t, rejected, 1)
+			assert.Equal(t, tc.attribute, rejected[0].Attribute)
+		})
+	}
+}
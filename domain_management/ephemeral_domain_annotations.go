@@ -0,0 +1,153 @@
+package domain_management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/myklst/terraform-provider-st-domain-management/api"
+	"github.com/myklst/terraform-provider-st-domain-management/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ ephemeral.EphemeralResourceWithConfigure = &domainAnnotationsEphemeralResource{}
+
+type domainAnnotationsEphemeralResourceModel struct {
+	Domain      types.String         `tfsdk:"domain"`
+	Keys        []string             `tfsdk:"keys"`
+	Annotations jsontypes.Normalized `tfsdk:"annotations"`
+}
+
+func NewDomainAnnotationEphemeralResource() ephemeral.EphemeralResource {
+	return &domainAnnotationsEphemeralResource{}
+}
+
+// domainAnnotationsEphemeralResource reads root keys of a domain for the
+// duration of a single Terraform run, without storing them in plan or state.
+type domainAnnotationsEphemeralResource struct {
+	client    *api.Client
+	keyPolicy *utils.KeyPolicy
+}
+
+func (r *domainAnnotationsEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_domain_annotations"
+}
+
+func (r *domainAnnotationsEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = providerData.Client
+	r.keyPolicy = providerData.KeyPolicy
+}
+
+func (r *domainAnnotationsEphemeralResource) Schema(_ context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Read root keys of a domain's annotations without storing them in plan or state.",
+		Attributes: map[string]schema.Attribute{
+			"domain": schema.StringAttribute{
+				Description: "The domain name to read annotations from.",
+				Required:    true,
+			},
+			"keys": schema.ListAttribute{
+				Description: "The root keys to read, e.g. `common/dns/challenge`.",
+				ElementType: types.StringType,
+				Required:    true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"annotations": schema.StringAttribute{
+				CustomType: jsontypes.NormalizedType{},
+				Description: "JSON formatted string of the root keys and their values. " +
+					"Suitable to use with terraform's built in jsondecode() function.",
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (r *domainAnnotationsEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	tflog.Info(ctx, "[ephemeralDomainAnnotationOpen!]")
+
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+
+	var config domainAnnotationsEphemeralResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keys := slices.Compact(slices.Sorted(slices.Values(config.Keys)))
+	resp.Diagnostics.Append(checkKeyPolicy(r.keyPolicy, path.Root("keys"), keys)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	payload, err := json.Marshal(keys)
+	if err != nil {
+		resp.Diagnostics.AddError("JSON Marshal Error", err.Error())
+		return
+	}
+
+	domain := config.Domain.ValueString()
+	annotationsResp, _, err := r.client.ReadAnnotations(ctx, domain, payload)
+	if errors.Is(err, api.ErrDomainNotFound) {
+		resp.Diagnostics.AddAttributeError(path.Root("domain"), "Domain not found", fmt.Sprintf("Domain %s does not exist.", domain))
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read annotations, got error: %s", err))
+		return
+	}
+
+	missing := []string{}
+	for _, k := range keys {
+		if _, found := annotationsResp[k]; !found {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("keys"),
+			"Annotations not found",
+			fmt.Sprintf("Domain %s has no root keys %s.", domain, strings.Join(missing, ", ")),
+		)
+		return
+	}
+
+	jsonStr, err := json.Marshal(annotationsResp)
+	if err != nil {
+		resp.Diagnostics.AddError("JSON Marshal Error", err.Error())
+		return
+	}
+	config.Annotations = jsontypes.NewNormalizedValue(string(jsonStr))
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, config)...)
+}
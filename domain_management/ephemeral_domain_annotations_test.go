@@ -0,0 +1,51 @@
+package domain_management
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ephemeralConfig(keys ...string) map[string]tftypes.Value {
+	keyValues := []tftypes.Value{}
+	for _, k := range keys {
+		keyValues = append(keyValues, stringValue(k))
+	}
+	return map[string]tftypes.Value{
+		"domain": stringValue(testDomain),
+		"keys":   tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, keyValues),
+	}
+}
+
+func TestEphemeralAnnotations(t *testing.T) {
+	p := newTestProvider(t, map[string]interface{}{
+		"common/dns/challenge": "token",
+		"common/other":         "untouched",
+	}, nil)
+
+	result, diags := p.openEphemeral(annotationsResourceType, ephemeralConfig("common/dns/challenge"))
+	requireNoErrors(t, diags)
+	assert.JSONEq(t, `{"common/dns/challenge": "token"}`, stringAttribute(t, result, "annotations"))
+}
+
+func TestEphemeralAnnotationsMissingKey(t *testing.T) {
+	p := newTestProvider(t, map[string]interface{}{"common/dns/challenge": "token"}, nil)
+
+	_, diags := p.openEphemeral(annotationsResourceType, ephemeralConfig("common/dns/challenge", "common/missing"))
+	require.Equal(t, []string{"Annotations not found"}, errorSummaries(diags))
+	assert.Equal(t, "Domain example.com has no root keys common/missing.", diags[0].Detail)
+
+	// None of the keys exist.
+	_, diags = p.openEphemeral(annotationsResourceType, ephemeralConfig("common/missing"))
+	assert.Equal(t, []string{"Annotations not found"}, errorSummaries(diags))
+}
+
+func TestEphemeralAnnotationsMissingDomain(t *testing.T) {
+	p := newTestProvider(t, map[string]interface{}{"common/dns/challenge": "token"}, nil)
+	p.backend.missing = true
+
+	_, diags := p.openEphemeral(annotationsResourceType, ephemeralConfig("common/dns/challenge"))
+	assert.Equal(t, []string{"Domain not found"}, errorSummaries(diags))
+}
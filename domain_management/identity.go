@@ -0,0 +1,90 @@
+package domain_management
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/myklst/terraform-provider-st-domain-management/domain_management/internal"
+
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.ResourceWithIdentity = &domainAnnotationsResource{}
+
+// The identity of a domain_annotations resource is its domain and the sorted
+// set of root keys it manages.
+type domainAnnotationsIdentityModel struct {
+	Domain types.String `tfsdk:"domain"`
+	Keys   []string     `tfsdk:"keys"`
+}
+
+func (r *domainAnnotationsResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"domain": identityschema.StringAttribute{
+				Description:       "The domain name of the annotations.",
+				RequiredForImport: true,
+			},
+			"keys": identityschema.ListAttribute{
+				Description:       "The root keys managed by the resource, sorted.",
+				ElementType:       types.StringType,
+				RequiredForImport: true,
+			},
+		},
+	}
+}
+
+// Sets the identity to the domain and the root keys of the annotations. The
+// keys are empty if the annotations are null, e.g. if only annotations_wo is
+// set, whose root keys are left out so that import never reads their values
+// into state. Nothing is set if the client does not support identities, or if
+// the annotations are unknown.
+func setAnnotationsIdentity(ctx context.Context, identity *tfsdk.ResourceIdentity, domain types.String, annotations jsontypes.Normalized) (diags diag.Diagnostics) {
+	if identity == nil || annotations.IsUnknown() {
+		return diags
+	}
+
+	annotationsObj := map[string]interface{}{}
+	if !annotations.IsNull() {
+		diags.Append(annotations.Unmarshal(&annotationsObj)...)
+	}
+	if diags.HasError() {
+		return diags
+	}
+	return setAnnotationsIdentityKeys(ctx, identity, domain.ValueString(), slices.Collect(maps.Keys(annotationsObj)))
+}
+
+func setAnnotationsIdentityKeys(ctx context.Context, identity *tfsdk.ResourceIdentity, domain string, keys []string) (diags diag.Diagnostics) {
+	if identity == nil {
+		return diags
+	}
+
+	keys = slices.Sorted(slices.Values(keys))
+	keys = slices.DeleteFunc(slices.Compact(keys), func(k string) bool {
+		return k == internal.OwnersAnnotationKey
+	})
+	return identity.Set(ctx, domainAnnotationsIdentityModel{
+		Domain: types.StringValue(domain),
+		Keys:   keys,
+	})
+}
+
+// Returns the import ID of an import block using identity instead of id.
+func importIDFromIdentity(ctx context.Context, identity *tfsdk.ResourceIdentity) (imported *internal.AnnotationsImportID, diags diag.Diagnostics) {
+	model := domainAnnotationsIdentityModel{}
+	diags.Append(identity.Get(ctx, &model)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return &internal.AnnotationsImportID{
+		Domain: model.Domain.ValueString(),
+		Keys:   model.Keys,
+	}, diags
+}
@@ -0,0 +1,61 @@
+package domain_management
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var annotationsIdentityType = tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+	"domain": tftypes.String,
+	"keys":   tftypes.List{ElementType: tftypes.String},
+}}
+
+// Returns the domain and keys of the identity.
+func identityValues(t *testing.T, identity *tfprotov6.ResourceIdentityData) (string, []string) {
+	require.NotNil(t, identity)
+	value, err := identity.IdentityData.Unmarshal(annotationsIdentityType)
+	require.NoError(t, err)
+
+	attrs := map[string]tftypes.Value{}
+	require.NoError(t, value.As(&attrs))
+	var domain string
+	require.NoError(t, attrs["domain"].As(&domain))
+	keyValues := []tftypes.Value{}
+	require.NoError(t, attrs["keys"].As(&keyValues))
+	keys := []string{}
+	for _, v := range keyValues {
+		var k string
+		require.NoError(t, v.As(&k))
+		keys = append(keys, k)
+	}
+	return domain, keys
+}
+
+func TestIdentityRoundTrip(t *testing.T) {
+	p := newTestProvider(t, map[string]interface{}{"common/other": "untouched"}, nil)
+
+	config := annotationsConfig(p, `{"common/b": "b", "common/a": {"x": 1}}`, map[string]tftypes.Value{
+		"owner": stringValue("module-a"),
+	})
+	created, diags := p.apply(annotationsResourceType, nil, config)
+	requireNoErrors(t, diags)
+
+	// The ownership records are not part of the identity.
+	domain, keys := identityValues(t, created.identity)
+	assert.Equal(t, testDomain, domain)
+	assert.Equal(t, []string{"common/a", "common/b"}, keys)
+
+	imported, diags := p.importState(annotationsResourceType, "", created.identity)
+	requireNoErrors(t, diags)
+	require.NotNil(t, imported)
+	assert.Equal(t, testDomain, stringAttribute(t, imported.value, "domain"))
+	assert.JSONEq(t, stringAttribute(t, created.value, "annotations"), stringAttribute(t, imported.value, "annotations"))
+
+	domain, keys = identityValues(t, imported.identity)
+	assert.Equal(t, testDomain, domain)
+	assert.Equal(t, []string{"common/a", "common/b"}, keys)
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	goPlayground "github.com/go-playground/validator"
+)
+
+// AnnotationsImportID is the parsed import ID of a domain_annotations
+// resource. It is either the JSON form
+//
+//	{"domain":"example.com","annotations":["common/a","common/b"]}
+//
+// or the simple form, where a key ending with "*" imports every root key
+// starting with what precedes it:
+//
+//	example.com:common/a,common/b
+//	example.com:common/*
+type AnnotationsImportID struct {
+	Domain   string   `json:"domain" validate:"required,hostname_rfc1123"`
+	Keys     []string `json:"annotations" validate:"dive,required"`
+	Prefixes []string `json:"-" validate:"dive,required"`
+}
+
+func ParseAnnotationsImportID(id string) (*AnnotationsImportID, error) {
+	imported := &AnnotationsImportID{}
+
+	if strings.HasPrefix(strings.TrimSpace(id), "{") {
+		if err := json.Unmarshal([]byte(id), imported); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal import ID: %w", err)
+		}
+	} else {
+		domain, keys, found := strings.Cut(id, ":")
+		if !found {
+			return nil, fmt.Errorf("expected <domain>:<key>[,<key>...] or <domain>:<prefix>*, got %q", id)
+		}
+
+		imported.Domain = domain
+		for _, key := range strings.Split(keys, ",") {
+			key = strings.TrimSpace(key)
+			if prefix, wildcard := strings.CutSuffix(key, "*"); wildcard {
+				imported.Prefixes = append(imported.Prefixes, prefix)
+			} else {
+				imported.Keys = append(imported.Keys, key)
+			}
+		}
+	}
+
+	if err := goPlayground.New().Struct(imported); err != nil {
+		return nil, err
+	}
+	if len(imported.Keys) == 0 && len(imported.Prefixes) == 0 {
+		return nil, fmt.Errorf("import ID %q contains no annotation keys", id)
+	}
+	return imported, nil
+}
+
+// Returns whether the root key is matched by any of the prefixes.
+func (i *AnnotationsImportID) MatchesPrefix(key string) bool {
+	for _, prefix := range i.Prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
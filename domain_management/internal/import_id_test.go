@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAnnotationsImportIDJSON(t *testing.T) {
+	imported, err := ParseAnnotationsImportID(`{"domain":"example.com","annotations":["common/a","common/b"]}`)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", imported.Domain)
+	assert.Equal(t, []string{"common/a", "common/b"}, imported.Keys)
+	assert.Empty(t, imported.Prefixes)
+}
+
+func TestParseAnnotationsImportIDSimple(t *testing.T) {
+	imported, err := ParseAnnotationsImportID("example.com:common/a, team-a/*")
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", imported.Domain)
+	assert.Equal(t, []string{"common/a"}, imported.Keys)
+	assert.Equal(t, []string{"team-a/"}, imported.Prefixes)
+
+	assert.True(t, imported.MatchesPrefix("team-a/status"))
+	assert.False(t, imported.MatchesPrefix("common/b"))
+}
+
+func TestParseAnnotationsImportIDInvalid(t *testing.T) {
+	for _, id := range []string{
+		"example.com",
+		"example.com:",
+		"example.com:common/a,,common/b",
+		"not a domain:common/a",
+		`{"domain":"example.com","annotations":[]}`,
+		`{"domain":"example.com"`,
+	} {
+		_, err := ParseAnnotationsImportID(id)
+		assert.Error(t, err, id)
+	}
+}
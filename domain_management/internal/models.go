@@ -1,7 +1,15 @@
 package internal
 
 import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/myklst/terraform-provider-st-domain-management/api"
@@ -14,16 +22,58 @@ type Filters struct {
 	Exclude basetypes.DynamicValue `tfsdk:"exclude" json:"exclude"`
 }
 
+// Returns the sorted keys used by both include and exclude.
+func (f *Filters) Keys() ([]string, error) {
+	keys := map[string]struct{}{}
+
+	for _, filter := range []basetypes.DynamicValue{f.Include, f.Exclude} {
+		obj, err := utils.TFTypesToJSON(filter)
+		if err != nil {
+			return nil, err
+		}
+		for k := range obj {
+			keys[k] = struct{}{}
+		}
+	}
+
+	return slices.Sorted(maps.Keys(keys)), nil
+}
+
 // The Terraform Types version of Filters. Used in schema implementation.
 var FilterAttributes = map[string]attr.Type{
 	"include": types.DynamicType,
 	"exclude": types.DynamicType,
 }
 
+// The output_typing attribute shared by the filter data sources.
+var OutputTypingAttribute = schema.StringAttribute{
+	Description: strings.Join([]string{
+		"How arrays and objects in `domains` are typed. Defaults to `tuple`.",
+		"  - `tuple` - Arrays are always tuples and objects are always objects.",
+		"  - `strict` - Arrays and objects whose elements share a single type become lists and maps,",
+		"which can be used directly in `for_each` without `tolist()`. Empty arrays and objects take the type of their siblings.",
+		"Objects with different keys never share a type, so e.g. domains holding different root keys stay a tuple.",
+	}, "\n"),
+	Optional: true,
+	Validators: []validator.String{
+		stringvalidator.OneOf(string(utils.TypingStrict), string(utils.TypingTuple)),
+	},
+}
+
+// Returns the typing mode selected by the output_typing attribute.
+func OutputTypingMode(outputTyping basetypes.StringValue) utils.TypingMode {
+	if outputTyping.IsNull() || outputTyping.IsUnknown() {
+		return utils.TypingTuple
+	}
+	return utils.TypingMode(outputTyping.ValueString())
+}
+
 type DomainFilterDataSourceModel struct {
 	DomainLabels      *Filters               `tfsdk:"domain_labels" json:"domain_labels"`
 	DomainAnnotations *Filters               `tfsdk:"domain_annotations" json:"domain_annotations"`
+	OutputTyping      basetypes.StringValue  `tfsdk:"output_typing" json:"output_typing"`
 	Domains           basetypes.DynamicValue `tfsdk:"domains" json:"domains"`
+	Timeouts          timeouts.Value         `tfsdk:"timeouts" json:"-"`
 }
 
 func (d *DomainFilterDataSourceModel) Payload() (api.DomainReq, error) {
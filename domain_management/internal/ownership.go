@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// OwnersAnnotationKey is the reserved root key under which the provider
+// records the owner of every root key, as an object of root key to owner.
+const OwnersAnnotationKey = "st-domain-management/owners"
+
+// Owners maps root keys to the owner that manages them.
+type Owners map[string]string
+
+// Parses the value stored under OwnersAnnotationKey. A nil value means no
+// ownership has been recorded yet.
+func ParseOwners(value interface{}) (Owners, error) {
+	owners := Owners{}
+	if value == nil {
+		return owners, nil
+	}
+
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an object, got %T", OwnersAnnotationKey, value)
+	}
+
+	for k, v := range obj {
+		owner, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("owner of %s must be a string, got %T", k, v)
+		}
+		owners[k] = owner
+	}
+	return owners, nil
+}
+
+// Returns the sorted keys that are owned by anyone other than the given
+// owners. Keys without an owner belong to nobody and are never returned.
+func (o Owners) Foreign(keys []string, ours ...string) []string {
+	foreign := []string{}
+	for _, k := range keys {
+		owner, found := o[k]
+		if !found || slices.Contains(ours, owner) {
+			continue
+		}
+		foreign = append(foreign, k)
+	}
+	slices.Sort(foreign)
+	return slices.Compact(foreign)
+}
+
+// Returns a copy where the keys are owned by owner. An empty owner removes
+// the records instead.
+func (o Owners) Assign(keys []string, owner string) Owners {
+	assigned := maps.Clone(o)
+	for _, k := range keys {
+		if owner == "" {
+			delete(assigned, k)
+		} else {
+			assigned[k] = owner
+		}
+	}
+	return assigned
+}
+
+// Returns a copy without records for the keys.
+func (o Owners) Release(keys []string) Owners {
+	return o.Assign(keys, "")
+}
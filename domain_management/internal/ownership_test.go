@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOwners(t *testing.T) {
+	owners, err := ParseOwners(nil)
+	require.NoError(t, err)
+	assert.Empty(t, owners)
+
+	owners, err = ParseOwners(map[string]interface{}{"common/a": "module-a"})
+	require.NoError(t, err)
+	assert.Equal(t, Owners{"common/a": "module-a"}, owners)
+
+	_, err = ParseOwners("module-a")
+	assert.Error(t, err)
+
+	_, err = ParseOwners(map[string]interface{}{"common/a": true})
+	assert.Error(t, err)
+}
+
+func TestOwnersForeign(t *testing.T) {
+	owners := Owners{"common/a": "module-a", "common/b": "module-b"}
+
+	assert.Empty(t, owners.Foreign([]string{"common/a", "common/c"}, "module-a"))
+	assert.Equal(t, []string{"common/b"}, owners.Foreign([]string{"common/a", "common/b", "common/b"}, "module-a"))
+	assert.Equal(t, []string{"common/a", "common/b"}, owners.Foreign([]string{"common/b", "common/a"}, ""))
+}
+
+func TestOwnersAssignAndRelease(t *testing.T) {
+	owners := Owners{"common/a": "module-a"}
+
+	assigned := owners.Assign([]string{"common/b"}, "module-b")
+	assert.Equal(t, Owners{"common/a": "module-a", "common/b": "module-b"}, assigned)
+	assert.Equal(t, Owners{"common/a": "module-a"}, owners, "Assign must not modify the receiver.")
+
+	assert.Equal(t, Owners{"common/b": "module-b"}, assigned.Release([]string{"common/a"}))
+	assert.Equal(t, Owners{"common/b": "module-b"}, assigned.Assign([]string{"common/a"}, ""))
+}
@@ -0,0 +1,25 @@
+package domain_management
+
+import (
+	"fmt"
+
+	"github.com/myklst/terraform-provider-st-domain-management/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// Checks annotation keys against the provider key policy. Every problem is
+// reported against the given attribute.
+func checkKeyPolicy(keyPolicy *utils.KeyPolicy, attribute path.Path, keys []string) (diags diag.Diagnostics) {
+	for _, key := range keys {
+		for _, problem := range keyPolicy.Check(key) {
+			diags.AddAttributeError(
+				attribute,
+				fmt.Sprintf("Annotation key %q violates the key policy", key),
+				problem,
+			)
+		}
+	}
+	return diags
+}
@@ -0,0 +1,188 @@
+package domain_management
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/myklst/terraform-provider-st-domain-management/api"
+	"github.com/myklst/terraform-provider-st-domain-management/domain_management/internal"
+	"github.com/myklst/terraform-provider-st-domain-management/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	listschema "github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ list.ListResourceWithConfigure = &domainAnnotationsListResource{}
+
+type domainAnnotationsListResourceModel struct {
+	DomainLabels      *internal.Filters `tfsdk:"domain_labels"`
+	DomainAnnotations *internal.Filters `tfsdk:"domain_annotations"`
+	KeyPrefix         types.String      `tfsdk:"key_prefix"`
+}
+
+func NewDomainAnnotationListResource() list.ListResource {
+	return &domainAnnotationsListResource{}
+}
+
+// domainAnnotationsListResource lists the annotations of every domain that
+// satisfies the filters as domain_annotations resources, so that
+// `terraform query` can generate import configuration for them.
+type domainAnnotationsListResource struct {
+	client    *api.Client
+	keyPolicy *utils.KeyPolicy
+}
+
+func (r *domainAnnotationsListResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_domain_annotations"
+}
+
+func (r *domainAnnotationsListResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected List Resource Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = providerData.Client
+	r.keyPolicy = providerData.KeyPolicy
+}
+
+func (r *domainAnnotationsListResource) ListResourceConfigSchema(_ context.Context, _ list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = listschema.Schema{
+		Description: "List the annotations of domains that satisfy the filter, one resource per domain.",
+		Attributes: map[string]listschema.Attribute{
+			"domain_labels": listschema.ObjectAttribute{
+				Description:    "Labels filter. Only domains that contain these labels are listed.",
+				AttributeTypes: internal.FilterAttributes,
+				Optional:       true,
+			},
+			"domain_annotations": listschema.ObjectAttribute{
+				Description:    "Annotations filter. Only domains that contain these annotations are listed.",
+				AttributeTypes: internal.FilterAttributes,
+				Optional:       true,
+			},
+			"key_prefix": listschema.StringAttribute{
+				Description: "Only root keys starting with this prefix are included in each resource, e.g. `team_a/`. " +
+					"Domains without such root keys are not listed. Defaults to every root key.",
+				Optional: true,
+			},
+		},
+	}
+}
+
+func (r *domainAnnotationsListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var config domainAnnotationsListResourceModel
+	diags := req.Config.Get(ctx, &config)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	if config.DomainAnnotations != nil {
+		keys, err := config.DomainAnnotations.Keys()
+		if err != nil {
+			diags.AddError("JSON Error", fmt.Sprintf("Cannot convert filter input to json: %s", err))
+			stream.Results = list.ListResultsStreamDiagnostics(diags)
+			return
+		}
+		diags.Append(checkKeyPolicy(r.keyPolicy, path.Root("domain_annotations"), keys)...)
+		if diags.HasError() {
+			stream.Results = list.ListResultsStreamDiagnostics(diags)
+			return
+		}
+	}
+
+	filter := internal.DomainFilterDataSourceModel{
+		DomainLabels:      config.DomainLabels,
+		DomainAnnotations: config.DomainAnnotations,
+	}
+	payload, err := filter.Payload()
+	if err != nil {
+		diags.AddError("JSON Error", fmt.Sprintf("Cannot convert filter input to json: %s", err))
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	// The results are streamed after List returns, so only the request itself
+	// is bounded.
+	getCtx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	domains, err := r.client.GetDomains(getCtx, payload)
+	cancel()
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read domains: %s", err))
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		var count int64
+		for _, domain := range domains {
+			if req.Limit > 0 && count >= req.Limit {
+				return
+			}
+
+			annotations := map[string]interface{}{}
+			for k, v := range domain.Metadata.Annotations {
+				if k != internal.OwnersAnnotationKey && strings.HasPrefix(k, config.KeyPrefix.ValueString()) {
+					annotations[k] = v
+				}
+			}
+			if len(annotations) == 0 {
+				continue
+			}
+
+			result := req.NewListResult(ctx)
+			result.DisplayName = domain.Domain
+			result.Diagnostics.Append(setAnnotationsIdentityKeys(ctx, result.Identity, domain.Domain, slices.Collect(maps.Keys(annotations)))...)
+			if req.IncludeResource {
+				result.Diagnostics.Append(listedAnnotationsResource(ctx, result, domain.Domain, annotations)...)
+			}
+
+			if !push(result) {
+				return
+			}
+			count++
+		}
+	}
+}
+
+// Sets the listed resource to the state import would produce.
+func listedAnnotationsResource(ctx context.Context, result list.ListResult, domain string, annotations map[string]interface{}) (diags diag.Diagnostics) {
+	jsonStr, err := json.Marshal(annotations)
+	if err != nil {
+		diags.AddError("JSON Marshal Error", err.Error())
+		return diags
+	}
+
+	return result.Resource.Set(ctx, domainAnnotationResourceModel{
+		Domain:               types.StringValue(domain),
+		Annotations:          jsontypes.NewNormalizedValue(string(jsonStr)),
+		AnnotationsValue:     utils.NewJSONDynamicNull(),
+		SensitiveAnnotations: jsontypes.NewNormalizedNull(),
+		AnnotationsWO:        jsontypes.NewNormalizedNull(),
+		AnnotationsWOVersion: types.Int64Null(),
+		OnConflict:           types.StringValue(onConflictError),
+		Revision:             types.StringNull(),
+		PlannedOperations:    types.ListNull(plannedOperationType),
+		DriftedKeys:          noDrift(),
+		Timeouts:             timeoutsNull(),
+	})
+}
@@ -0,0 +1,60 @@
+package domain_management
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/myklst/terraform-provider-st-domain-management/domain_management/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listAnnotations() map[string]interface{} {
+	return map[string]interface{}{
+		"team-a/status":              map[string]interface{}{"live": true},
+		"team-a/owner":               "a@example.com",
+		"team-b/status":              "down",
+		internal.OwnersAnnotationKey: map[string]interface{}{"team-a/status": "module-a"},
+	}
+}
+
+func TestListAnnotations(t *testing.T) {
+	p := newTestProvider(t, listAnnotations(), nil)
+
+	results := p.list(annotationsResourceType, map[string]tftypes.Value{
+		"key_prefix": stringValue("team-a/"),
+	}, true)
+	require.Len(t, results, 1)
+	requireNoErrors(t, results[0].Diagnostics)
+	assert.Equal(t, testDomain, results[0].DisplayName)
+
+	// The ownership records are left out, like import does.
+	domain, keys := identityValues(t, results[0].Identity)
+	assert.Equal(t, testDomain, domain)
+	assert.Equal(t, []string{"team-a/owner", "team-a/status"}, keys)
+
+	listed := p.value(p.resourceSchema(annotationsResourceType).ValueType(), results[0].Resource)
+	assert.Equal(t, testDomain, stringAttribute(t, listed, "domain"))
+	assert.JSONEq(t, `{"team-a/owner": "a@example.com", "team-a/status": {"live": true}}`, stringAttribute(t, listed, "annotations"))
+	assert.Equal(t, onConflictError, stringAttribute(t, listed, "on_conflict"))
+}
+
+func TestListAnnotationsWithoutResource(t *testing.T) {
+	p := newTestProvider(t, listAnnotations(), nil)
+
+	results := p.list(annotationsResourceType, nil, false)
+	require.Len(t, results, 1)
+	assert.Nil(t, results[0].Resource)
+
+	_, keys := identityValues(t, results[0].Identity)
+	assert.Equal(t, []string{"team-a/owner", "team-a/status", "team-b/status"}, keys)
+}
+
+func TestListAnnotationsNoMatchingKeys(t *testing.T) {
+	p := newTestProvider(t, listAnnotations(), nil)
+
+	results := p.list(annotationsResourceType, map[string]tftypes.Value{
+		"key_prefix": stringValue("team-z/"),
+	}, true)
+	assert.Empty(t, results)
+}
@@ -0,0 +1,32 @@
+package domain_management
+
+import (
+	"context"
+	"strings"
+
+	"github.com/myklst/terraform-provider-st-domain-management/domain_management/internal"
+)
+
+// Reads the root keys of the domain that start with the managed prefix but
+// are not managed by this resource, along with their values.
+func (r *domainAnnotationsResource) readStrayAnnotations(ctx context.Context, domain string, prefix string, managed map[string]interface{}) (map[string]interface{}, error) {
+	strays := map[string]interface{}{}
+	if prefix == "" {
+		return strays, nil
+	}
+
+	domainResp, err := r.client.GetDomain(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range domainResp.Metadata.Annotations {
+		if k == internal.OwnersAnnotationKey || !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		if _, found := managed[k]; !found {
+			strays[k] = v
+		}
+	}
+	return strays, nil
+}
@@ -0,0 +1,126 @@
+package domain_management
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/myklst/terraform-provider-st-domain-management/api"
+	"github.com/myklst/terraform-provider-st-domain-management/domain_management/internal"
+	"github.com/myklst/terraform-provider-st-domain-management/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"gomodules.xyz/jsonpatch/v2"
+)
+
+// Reads the ownership records of the domain, along with the current values
+// of the given keys. Returns whether the records exist, and the revision
+// everything was read at.
+func readOwners(ctx context.Context, client *api.Client, domain string, keys ...string) (owners internal.Owners, exists bool, existing map[string]interface{}, revision string, err error) {
+	payload, err := json.Marshal(append([]string{internal.OwnersAnnotationKey}, keys...))
+	if err != nil {
+		return nil, false, nil, "", err
+	}
+
+	annotationsResp, revision, err := client.ReadAnnotations(ctx, domain, payload)
+	if err != nil {
+		return nil, false, nil, "", err
+	}
+
+	value, exists := annotationsResp[internal.OwnersAnnotationKey]
+	owners, err = internal.ParseOwners(value)
+	if err != nil {
+		return nil, false, nil, "", err
+	}
+
+	existing = map[string]interface{}{}
+	for _, k := range keys {
+		if v, found := annotationsResp[k]; found {
+			existing[k] = v
+		}
+	}
+	return owners, exists, existing, revision, nil
+}
+
+// Refuses keys owned by anyone other than ours, unless force_takeover is set.
+// Each refusal is reported on the attribute keyPath returns for the key.
+func checkOwnership(owners internal.Owners, keys []string, keyPath func(string) path.Path, forceTakeover types.Bool, ours ...types.String) (diags diag.Diagnostics) {
+	if forceTakeover.ValueBool() {
+		return diags
+	}
+
+	ourOwners := []string{}
+	for _, owner := range ours {
+		if !owner.IsNull() && !owner.IsUnknown() {
+			ourOwners = append(ourOwners, owner.ValueString())
+		}
+	}
+
+	for _, key := range owners.Foreign(keys, ourOwners...) {
+		diags.AddAttributeError(
+			keyPath(key),
+			fmt.Sprintf("Annotation key %q is owned by %q", key, owners[key]),
+			"The key is managed by another owner. Set force_takeover = true to take it over.",
+		)
+	}
+	return diags
+}
+
+// Returns the annotations as held by the backend including the ownership
+// records, suitable as the starting point of a transaction.
+func withOwners(annotations map[string]interface{}, owners internal.Owners, exists bool) map[string]interface{} {
+	current := maps.Clone(annotations)
+	if current == nil {
+		current = map[string]interface{}{}
+	}
+	if exists {
+		current[internal.OwnersAnnotationKey] = owners
+	}
+	return current
+}
+
+// Returns the JSON Patch operation that turns the previous ownership records
+// into the next ones, if they differ.
+func ownersPatch(previous, next internal.Owners, exists bool) []jsonpatch.Operation {
+	if maps.Equal(previous, next) {
+		return nil
+	}
+
+	path := "/" + utils.EscapeString(internal.OwnersAnnotationKey)
+	switch {
+	case len(next) == 0:
+		return []jsonpatch.Operation{jsonpatch.NewOperation("remove", path, nil)}
+	case exists:
+		return []jsonpatch.Operation{jsonpatch.NewOperation("replace", path, next)}
+	default:
+		return []jsonpatch.Operation{jsonpatch.NewOperation("add", path, next)}
+	}
+}
+
+// Writes the next ownership records as a step of the transaction, if they
+// differ from the previous ones.
+func (t *annotationsTransaction) SetOwners(previous, next internal.Owners, exists bool) error {
+	if maps.Equal(previous, next) {
+		return nil
+	}
+
+	switch {
+	case len(next) == 0:
+		return t.Delete([]string{internal.OwnersAnnotationKey})
+	case exists:
+		return t.Update(map[string]interface{}{internal.OwnersAnnotationKey: next})
+	default:
+		return t.Create(map[string]interface{}{internal.OwnersAnnotationKey: next})
+	}
+}
+
+// Returns the annotations the backend is known to hold, without the
+// ownership records.
+func (t *annotationsTransaction) Annotations() map[string]interface{} {
+	annotations := maps.Clone(t.Current)
+	delete(annotations, internal.OwnersAnnotationKey)
+	return annotations
+}
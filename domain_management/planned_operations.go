@@ -0,0 +1,112 @@
+package domain_management
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/myklst/terraform-provider-st-domain-management/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Values of operation in planned_operations.
+const (
+	plannedOperationCreate = "create"
+	plannedOperationUpdate = "update"
+	plannedOperationDelete = "delete"
+)
+
+type plannedOperationModel struct {
+	Operation types.String `tfsdk:"operation"`
+	Key       types.String `tfsdk:"key"`
+	Paths     []string     `tfsdk:"paths"`
+}
+
+var plannedOperationAttributes = map[string]attr.Type{
+	"operation": types.StringType,
+	"key":       types.StringType,
+	"paths":     types.ListType{ElemType: types.StringType},
+}
+
+var plannedOperationType = types.ObjectType{AttrTypes: plannedOperationAttributes}
+
+// Returns the root key operations that turn the prior annotations into the
+// planned ones. If the annotations do not change semantically, the previous
+// operations are kept, so that the attribute only changes along with them.
+func plannedOperations(ctx context.Context, prior, planned jsontypes.Normalized, previous types.List) (operations []plannedOperationModel, value types.List, diags diag.Diagnostics) {
+	if planned.IsUnknown() {
+		return nil, types.ListUnknown(plannedOperationType), diags
+	}
+
+	if !prior.IsNull() && !prior.IsUnknown() && !planned.IsNull() {
+		equal, equalDiags := prior.StringSemanticEquals(ctx, planned)
+		diags.Append(equalDiags...)
+		if diags.HasError() {
+			return nil, previous, diags
+		}
+		if equal {
+			return nil, previous, diags
+		}
+	}
+
+	priorString := "{}"
+	if !prior.IsNull() && !prior.IsUnknown() {
+		priorString = prior.ValueString()
+	}
+	plannedString := "{}"
+	if !planned.IsNull() {
+		plannedString = planned.ValueString()
+	}
+
+	updateOp, err := utils.JSONDiffToTerraformOperations([]byte(priorString), []byte(plannedString))
+	if err != nil {
+		diags.AddError("JSON Diff Error", err.Error())
+		return nil, previous, diags
+	}
+
+	nestedPaths := updateOp.NestedPaths()
+	operations = []plannedOperationModel{}
+	for _, group := range []struct {
+		operation string
+		keys      []string
+	}{
+		{plannedOperationCreate, slices.Sorted(maps.Keys(updateOp.Create))},
+		{plannedOperationUpdate, slices.Sorted(maps.Keys(updateOp.Update))},
+		{plannedOperationDelete, slices.Sorted(maps.Keys(updateOp.Delete))},
+	} {
+		for _, k := range group.keys {
+			paths := []string{}
+			if group.operation == plannedOperationUpdate {
+				paths = append(paths, nestedPaths[k]...)
+			}
+			operations = append(operations, plannedOperationModel{
+				Operation: types.StringValue(group.operation),
+				Key:       types.StringValue(k),
+				Paths:     paths,
+			})
+		}
+	}
+
+	value, listDiags := types.ListValueFrom(ctx, plannedOperationType, operations)
+	diags.Append(listDiags...)
+	return operations, value, diags
+}
+
+// Formats the operations for the plan summary, one root key per line,
+// followed by the nested paths changed inside it.
+func plannedOperationsSummary(operations []plannedOperationModel) string {
+	var sb strings.Builder
+	for _, operation := range operations {
+		fmt.Fprintf(&sb, "  %-6s %s\n", operation.Operation.ValueString(), operation.Key.ValueString())
+		for _, p := range operation.Paths {
+			fmt.Fprintf(&sb, "           %s\n", p)
+		}
+	}
+	return sb.String()
+}
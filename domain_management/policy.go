@@ -0,0 +1,42 @@
+package domain_management
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/myklst/terraform-provider-st-domain-management/api"
+	"github.com/myklst/terraform-provider-st-domain-management/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// Evaluates the provider policy against the current labels of the domain,
+// fetched from the backend, and the planned annotations. Violations are
+// reported on the attribute.
+func checkPolicy(ctx context.Context, client *api.Client, policy *utils.Policy, attribute path.Path, domain string, annotations map[string]interface{}) (diags diag.Diagnostics) {
+	if !policy.HasRules() {
+		return diags
+	}
+
+	domainResp, err := client.GetDomain(ctx, domain)
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read labels of %s for policy evaluation, got error: %s", domain, err))
+		return diags
+	}
+
+	for _, violation := range policy.Evaluate(domain, domainResp.Metadata.Labels, annotations) {
+		summary := fmt.Sprintf("Policy rule %q violated", violation.Rule.Name)
+		detail := violation.Detail
+		if violation.Rule.Message != "" {
+			detail = violation.Rule.Message + "\n\n" + detail
+		}
+
+		if violation.Rule.Severity == utils.PolicySeverityWarning {
+			diags.AddAttributeWarning(attribute, summary, detail)
+		} else {
+			diags.AddAttributeError(attribute, summary, detail)
+		}
+	}
+	return diags
+}
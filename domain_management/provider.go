@@ -3,25 +3,59 @@ package domain_management
 import (
 	"context"
 	"os"
+	"strings"
 
+	"github.com/myklst/terraform-provider-st-domain-management/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/list"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 var _ provider.Provider = &DomainManagementProvider{}
 var _ provider.ProviderWithFunctions = &DomainManagementProvider{}
+var _ provider.ProviderWithListResources = &DomainManagementProvider{}
+var _ provider.ProviderWithEphemeralResources = &DomainManagementProvider{}
 
 type DomainManagementProvider struct {
 	version string
 }
 
 type DomainManagementProviderModel struct {
-	Endpoint types.String `tfsdk:"endpoint"`
+	Endpoint          types.String    `tfsdk:"endpoint"`
+	AnnotationSchemas types.Map       `tfsdk:"annotation_schemas"`
+	ValidateOnPlan    types.Bool      `tfsdk:"validate_on_plan"`
+	JSONPatch         types.Bool      `tfsdk:"json_patch"`
+	KeyPolicy         *keyPolicyModel `tfsdk:"key_policy"`
+	Policy            *policyModel    `tfsdk:"policy"`
+}
+
+type keyPolicyModel struct {
+	PrefixPattern      types.String `tfsdk:"prefix_pattern"`
+	AllowedCharacters  types.String `tfsdk:"allowed_characters"`
+	MaxLength          types.Int64  `tfsdk:"max_length"`
+	ReservedPrefixes   types.List   `tfsdk:"reserved_prefixes"`
+	DNSSubdomainPrefix types.Bool   `tfsdk:"dns_subdomain_prefix"`
+}
+
+type policyModel struct {
+	Rules []policyRuleModel `tfsdk:"rule"`
+}
+
+type policyRuleModel struct {
+	Name       types.String `tfsdk:"name"`
+	Expression types.String `tfsdk:"expression"`
+	Message    types.String `tfsdk:"message"`
+	Severity   types.String `tfsdk:"severity"`
 }
 
 func New() provider.Provider {
@@ -40,6 +74,97 @@ func (p *DomainManagementProvider) Schema(ctx context.Context, req provider.Sche
 				MarkdownDescription: "The Domain Management server endpoint",
 				Optional:            true,
 			},
+			"annotation_schemas": schema.MapAttribute{
+				MarkdownDescription: strings.Join([]string{
+					"JSON Schemas that annotation values must conform to, keyed by root key or key glob, e.g. `common/*`.",
+					"Each value is either an inline JSON Schema, suitable to use with `jsonencode()`, or a path to a JSON Schema file.",
+					"The value of every matching root key in `st-domain-management_domain_annotations` is validated at plan time,",
+					"as is the root key `st-domain-management_domain_annotation_path` writes into, with the value at the path set.",
+				}, "\n"),
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"validate_on_plan": schema.BoolAttribute{
+				MarkdownDescription: strings.Join([]string{
+					"Have the server validate the planned annotation writes of `st-domain-management_domain_annotations` during plan,",
+					"using dry-run requests that are not committed. Errors such as size limits or forbidden keys are then reported by `terraform plan`.",
+				}, "\n"),
+				Optional: true,
+			},
+			"json_patch": schema.BoolAttribute{
+				MarkdownDescription: strings.Join([]string{
+					"Send the changes of an update as a single JSON Patch request (`application/json-patch+json`), which the server applies atomically.",
+					"Only enable it if the server supports JSON Patch. If the server responds that it does not support JSON Patch (`415`, `405` or `501`), the changes are sent as separate requests instead.",
+				}, "\n"),
+				Optional: true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"key_policy": schema.SingleNestedBlock{
+				MarkdownDescription: strings.Join([]string{
+					"Naming policy for annotation keys, enforced at plan time by `st-domain-management_domain_annotations`",
+					"and by the annotations filter of the data sources. Keys are in the form of `prefix/name`,",
+					"where the prefix is everything before the first `/`.",
+				}, "\n"),
+				Attributes: map[string]schema.Attribute{
+					"prefix_pattern": schema.StringAttribute{
+						MarkdownDescription: "Regular expression the whole prefix must match. When set, every key must have a prefix.",
+						Optional:            true,
+					},
+					"allowed_characters": schema.StringAttribute{
+						MarkdownDescription: "Characters allowed in the key, written as the content of a regular expression bracket expression, e.g. `a-z0-9/-`.",
+						Optional:            true,
+					},
+					"max_length": schema.Int64Attribute{
+						MarkdownDescription: "Maximum length of the whole key.",
+						Optional:            true,
+					},
+					"reserved_prefixes": schema.ListAttribute{
+						MarkdownDescription: "Keys starting with any of these prefixes are rejected.",
+						ElementType:         types.StringType,
+						Optional:            true,
+					},
+					"dns_subdomain_prefix": schema.BoolAttribute{
+						MarkdownDescription: "Require the prefix to be a valid lowercase DNS label, e.g. `example`. Since keys must not contain `.`, a prefix of several labels such as `example.com` is rejected.",
+						Optional:            true,
+					},
+				},
+			},
+			"policy": schema.SingleNestedBlock{
+				MarkdownDescription: strings.Join([]string{
+					"Guardrails evaluated at plan time by `st-domain-management_domain_annotations` and `st-domain-management_domain_annotation_path`.",
+					"Each rule is a [CEL](https://cel.dev) expression that must evaluate to `true`. The expression can use",
+					"`domain` (string), `labels` (the current labels of the domain, fetched from the backend)",
+					"and `annotations` (the planned annotations, or the root key holding the planned value at the path).",
+				}, "\n"),
+				Blocks: map[string]schema.Block{
+					"rule": schema.ListNestedBlock{
+						NestedObject: schema.NestedBlockObject{
+							Attributes: map[string]schema.Attribute{
+								"name": schema.StringAttribute{
+									MarkdownDescription: "Name of the rule, shown when the rule is violated.",
+									Required:            true,
+								},
+								"expression": schema.StringAttribute{
+									MarkdownDescription: "CEL expression that must evaluate to `true`, e.g. `!(labels[\"common/env\"] == \"prod\" && annotations[\"common/devops/status\"] == false)`.",
+									Required:            true,
+								},
+								"message": schema.StringAttribute{
+									MarkdownDescription: "Explanation shown when the rule is violated.",
+									Optional:            true,
+								},
+								"severity": schema.StringAttribute{
+									MarkdownDescription: "Either `error` or `warning`. Defaults to `error`.",
+									Optional:            true,
+									Validators: []validator.String{
+										stringvalidator.OneOf(string(utils.PolicySeverityError), string(utils.PolicySeverityWarning)),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -90,22 +215,106 @@ func (p *DomainManagementProvider) Configure(ctx context.Context, req provider.C
 	}
 
 	cfg := Config{
-		Endpoint: endpoint,
+		Endpoint:       endpoint,
+		ValidateOnPlan: config.ValidateOnPlan.ValueBool(),
+		JSONPatch:      config.JSONPatch.ValueBool(),
+	}
+
+	if !config.AnnotationSchemas.IsNull() {
+		schemas := map[string]string{}
+		resp.Diagnostics.Append(config.AnnotationSchemas.ElementsAs(ctx, &schemas, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		annotationSchemas, err := utils.NewAnnotationSchemas(schemas)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("annotation_schemas"), "Invalid annotation schema", err.Error())
+			return
+		}
+		cfg.AnnotationSchemas = annotationSchemas
 	}
 
-	client, err := cfg.Client()
+	if config.KeyPolicy != nil {
+		cfg.KeyPolicy, diags = config.KeyPolicy.toKeyPolicy(ctx)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if config.Policy != nil {
+		cfg.Policy, diags = config.Policy.toPolicy()
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	providerData, err := cfg.ProviderData()
 	if err != nil {
 		resp.Diagnostics.AddError("Create Domain Management API client Error", err.Error())
 		return
 	}
 
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	resp.DataSourceData = providerData
+	resp.ResourceData = providerData
+	resp.ListResourceData = providerData
+	resp.EphemeralResourceData = providerData
+}
+
+func (m *keyPolicyModel) toKeyPolicy(ctx context.Context) (*utils.KeyPolicy, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	reservedPrefixes := []string{}
+	if !m.ReservedPrefixes.IsNull() {
+		diags.Append(m.ReservedPrefixes.ElementsAs(ctx, &reservedPrefixes, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+	}
+
+	keyPolicy, err := utils.NewKeyPolicy(
+		m.PrefixPattern.ValueString(),
+		m.AllowedCharacters.ValueString(),
+		m.MaxLength.ValueInt64(),
+		reservedPrefixes,
+		m.DNSSubdomainPrefix.ValueBool(),
+	)
+	if err != nil {
+		diags.AddAttributeError(path.Root("key_policy"), "Invalid key policy", err.Error())
+		return nil, diags
+	}
+
+	return keyPolicy, diags
+}
+
+func (m *policyModel) toPolicy() (*utils.Policy, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	rules := []utils.PolicyRule{}
+	for _, rule := range m.Rules {
+		rules = append(rules, utils.PolicyRule{
+			Name:       rule.Name.ValueString(),
+			Expression: rule.Expression.ValueString(),
+			Message:    rule.Message.ValueString(),
+			Severity:   utils.PolicySeverity(rule.Severity.ValueString()),
+		})
+	}
+
+	policy, err := utils.NewPolicy(rules)
+	if err != nil {
+		diags.AddAttributeError(path.Root("policy"), "Invalid policy", err.Error())
+		return nil, diags
+	}
+
+	return policy, diags
 }
 
 func (p *DomainManagementProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewDomainAnnotationResource,
+		NewDomainAnnotationPathResource,
 	}
 }
 
@@ -116,6 +325,18 @@ func (p *DomainManagementProvider) DataSources(_ context.Context) []func() datas
 	}
 }
 
+func (p *DomainManagementProvider) ListResources(_ context.Context) []func() list.ListResource {
+	return []func() list.ListResource{
+		NewDomainAnnotationListResource,
+	}
+}
+
+func (p *DomainManagementProvider) EphemeralResources(_ context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewDomainAnnotationEphemeralResource,
+	}
+}
+
 func (p *DomainManagementProvider) Functions(ctx context.Context) []func() function.Function {
 	return []func() function.Function{}
 }
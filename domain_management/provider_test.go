@@ -0,0 +1,325 @@
+package domain_management
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/require"
+)
+
+const annotationsResourceType = "st-domain-management_domain_annotations"
+
+// testProvider drives the provider through the plugin protocol, the way
+// Terraform does, against a fake backend.
+type testProvider struct {
+	t       *testing.T
+	server  tfprotov6.ProviderServer
+	schemas *tfprotov6.GetProviderSchemaResponse
+	backend *fakeBackend
+}
+
+// The state of a managed resource between operations.
+type testResourceState struct {
+	value    tftypes.Value
+	private  []byte
+	identity *tfprotov6.ResourceIdentityData
+}
+
+// Returns a provider configured with the attributes, whose backend initially
+// holds the annotations.
+func newTestProvider(t *testing.T, annotations map[string]interface{}, config map[string]tftypes.Value) *testProvider {
+	ctx := context.Background()
+	backend, httpServer := newFakeServer(t, annotations)
+
+	server := providerserver.NewProtocol6(New())()
+	schemas, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	require.NoError(t, err)
+	requireNoErrors(t, schemas.Diagnostics)
+
+	p := &testProvider{t: t, server: server, schemas: schemas, backend: backend}
+
+	if config == nil {
+		config = map[string]tftypes.Value{}
+	}
+	config["endpoint"] = tftypes.NewValue(tftypes.String, httpServer.URL)
+	resp, err := server.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{
+		Config: p.dynamicValue(objectValue(schemas.Provider.ValueType(), config)),
+	})
+	require.NoError(t, err)
+	requireNoErrors(t, resp.Diagnostics)
+	return p
+}
+
+func (p *testProvider) dynamicValue(value tftypes.Value) *tfprotov6.DynamicValue {
+	dv, err := tfprotov6.NewDynamicValue(value.Type(), value)
+	require.NoError(p.t, err)
+	return &dv
+}
+
+func (p *testProvider) value(typ tftypes.Type, dv *tfprotov6.DynamicValue) tftypes.Value {
+	if dv == nil {
+		return tftypes.NewValue(typ, nil)
+	}
+	value, err := dv.Unmarshal(typ)
+	require.NoError(p.t, err)
+	return value
+}
+
+func (p *testProvider) resourceSchema(typeName string) *tfprotov6.Schema {
+	schema, found := p.schemas.ResourceSchemas[typeName]
+	require.True(p.t, found, typeName)
+	return schema
+}
+
+// Returns the configuration of the resource with the given attributes, and
+// every other attribute null.
+func (p *testProvider) resourceConfig(typeName string, attrs map[string]tftypes.Value) tftypes.Value {
+	return objectValue(p.resourceSchema(typeName).ValueType(), attrs)
+}
+
+// Validates the configuration of the resource.
+func (p *testProvider) validate(typeName string, config tftypes.Value) []*tfprotov6.Diagnostic {
+	resp, err := p.server.ValidateResourceConfig(context.Background(), &tfprotov6.ValidateResourceConfigRequest{
+		TypeName: typeName,
+		Config:   p.dynamicValue(config),
+		ClientCapabilities: &tfprotov6.ValidateResourceConfigClientCapabilities{
+			WriteOnlyAttributesAllowed: true,
+		},
+	})
+	require.NoError(p.t, err)
+	return resp.Diagnostics
+}
+
+// Plans and applies the configuration, like terraform apply. A null config
+// destroys the resource. Returns the new state, which is nil once the
+// resource is gone, along with the diagnostics of the steps taken.
+func (p *testProvider) apply(typeName string, prior *testResourceState, config tftypes.Value) (*testResourceState, []*tfprotov6.Diagnostic) {
+	ctx := context.Background()
+	schema := p.resourceSchema(typeName)
+	typ := schema.ValueType()
+
+	if !config.IsNull() {
+		if diags := p.validate(typeName, config); hasErrors(diags) {
+			return prior, diags
+		}
+	}
+
+	priorValue := tftypes.NewValue(typ, nil)
+	var priorPrivate []byte
+	var priorIdentity *tfprotov6.ResourceIdentityData
+	if prior != nil {
+		priorValue, priorPrivate, priorIdentity = prior.value, prior.private, prior.identity
+	}
+
+	planResp, err := p.server.PlanResourceChange(ctx, &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       p.dynamicValue(priorValue),
+		ProposedNewState: p.dynamicValue(proposedNewState(schema, priorValue, config)),
+		Config:           p.dynamicValue(config),
+		PriorPrivate:     priorPrivate,
+		PriorIdentity:    priorIdentity,
+	})
+	require.NoError(p.t, err)
+	if hasErrors(planResp.Diagnostics) {
+		return prior, planResp.Diagnostics
+	}
+
+	applyResp, err := p.server.ApplyResourceChange(ctx, &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:        typeName,
+		PriorState:      p.dynamicValue(priorValue),
+		PlannedState:    planResp.PlannedState,
+		Config:          p.dynamicValue(config),
+		PlannedPrivate:  planResp.PlannedPrivate,
+		PlannedIdentity: planResp.PlannedIdentity,
+	})
+	require.NoError(p.t, err)
+
+	diags := append(planResp.Diagnostics, applyResp.Diagnostics...)
+	newValue := p.value(typ, applyResp.NewState)
+	if newValue.IsNull() {
+		return nil, diags
+	}
+	return &testResourceState{
+		value:    newValue,
+		private:  applyResp.Private,
+		identity: applyResp.NewIdentity,
+	}, diags
+}
+
+// Refreshes the state. Returns nil if the resource is gone.
+func (p *testProvider) read(typeName string, current *testResourceState) (*testResourceState, []*tfprotov6.Diagnostic) {
+	typ := p.resourceSchema(typeName).ValueType()
+
+	resp, err := p.server.ReadResource(context.Background(), &tfprotov6.ReadResourceRequest{
+		TypeName:        typeName,
+		CurrentState:    p.dynamicValue(current.value),
+		Private:         current.private,
+		CurrentIdentity: current.identity,
+	})
+	require.NoError(p.t, err)
+
+	newValue := p.value(typ, resp.NewState)
+	if newValue.IsNull() {
+		return nil, resp.Diagnostics
+	}
+	return &testResourceState{
+		value:    newValue,
+		private:  resp.Private,
+		identity: resp.NewIdentity,
+	}, resp.Diagnostics
+}
+
+// Imports the resource by import ID or, if id is empty, by identity, and
+// refreshes it like terraform import does.
+func (p *testProvider) importState(typeName string, id string, identity *tfprotov6.ResourceIdentityData) (*testResourceState, []*tfprotov6.Diagnostic) {
+	resp, err := p.server.ImportResourceState(context.Background(), &tfprotov6.ImportResourceStateRequest{
+		TypeName: typeName,
+		ID:       id,
+		Identity: identity,
+	})
+	require.NoError(p.t, err)
+	if hasErrors(resp.Diagnostics) {
+		return nil, resp.Diagnostics
+	}
+	require.Len(p.t, resp.ImportedResources, 1)
+
+	imported := resp.ImportedResources[0]
+	return p.read(typeName, &testResourceState{
+		value:    p.value(p.resourceSchema(typeName).ValueType(), imported.State),
+		private:  imported.Private,
+		identity: imported.Identity,
+	})
+}
+
+// Runs the list resource with the given configuration attributes, like
+// terraform query, and returns every result.
+func (p *testProvider) list(typeName string, attrs map[string]tftypes.Value, includeResource bool) []tfprotov6.ListResourceResult {
+	schema, found := p.schemas.ListResourceSchemas[typeName]
+	require.True(p.t, found, typeName)
+
+	server, ok := p.server.(tfprotov6.ProviderServerWithListResource)
+	require.True(p.t, ok)
+	stream, err := server.ListResource(context.Background(), &tfprotov6.ListResourceRequest{
+		TypeName:        typeName,
+		Config:          p.dynamicValue(objectValue(schema.ValueType(), attrs)),
+		IncludeResource: includeResource,
+	})
+	require.NoError(p.t, err)
+
+	results := []tfprotov6.ListResourceResult{}
+	for result := range stream.Results {
+		results = append(results, result)
+	}
+	return results
+}
+
+// Opens the ephemeral resource with the given configuration attributes.
+// Returns its result, which is null if opening it failed.
+func (p *testProvider) openEphemeral(typeName string, attrs map[string]tftypes.Value) (tftypes.Value, []*tfprotov6.Diagnostic) {
+	schema, found := p.schemas.EphemeralResourceSchemas[typeName]
+	require.True(p.t, found, typeName)
+	typ := schema.ValueType()
+
+	resp, err := p.server.OpenEphemeralResource(context.Background(), &tfprotov6.OpenEphemeralResourceRequest{
+		TypeName: typeName,
+		Config:   p.dynamicValue(objectValue(typ, attrs)),
+	})
+	require.NoError(p.t, err)
+	return p.value(typ, resp.Result), resp.Diagnostics
+}
+
+// Returns the value of the attribute of the state.
+func attribute(t *testing.T, state tftypes.Value, name string) tftypes.Value {
+	attrs := map[string]tftypes.Value{}
+	require.NoError(t, state.As(&attrs))
+	return attrs[name]
+}
+
+// Returns the value of a string attribute of the state, empty if it is null.
+func stringAttribute(t *testing.T, state tftypes.Value, name string) string {
+	var s *string
+	require.NoError(t, attribute(t, state, name).As(&s))
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// Returns the object with the given attributes, and every other attribute
+// null.
+func objectValue(typ tftypes.Type, attrs map[string]tftypes.Value) tftypes.Value {
+	objectType := typ.(tftypes.Object)
+	values := map[string]tftypes.Value{}
+	for name, attrType := range objectType.AttributeTypes {
+		if value, found := attrs[name]; found {
+			values[name] = value
+		} else {
+			values[name] = tftypes.NewValue(attrType, nil)
+		}
+	}
+	return tftypes.NewValue(objectType, values)
+}
+
+// Returns the proposed new state Terraform sends with a plan: the
+// configuration, with computed attributes left out of it taken from the prior
+// state, and write-only attributes null.
+func proposedNewState(schema *tfprotov6.Schema, prior, config tftypes.Value) tftypes.Value {
+	if config.IsNull() {
+		return config
+	}
+
+	configAttrs := map[string]tftypes.Value{}
+	if err := config.As(&configAttrs); err != nil {
+		panic(err)
+	}
+	priorAttrs := map[string]tftypes.Value{}
+	if !prior.IsNull() {
+		if err := prior.As(&priorAttrs); err != nil {
+			panic(err)
+		}
+	}
+
+	proposed := map[string]tftypes.Value{}
+	for name, value := range configAttrs {
+		proposed[name] = value
+	}
+	for _, attr := range schema.Block.Attributes {
+		switch {
+		case attr.WriteOnly:
+			proposed[attr.Name] = tftypes.NewValue(attr.ValueType(), nil)
+		case attr.Computed && configAttrs[attr.Name].IsNull() && !prior.IsNull():
+			proposed[attr.Name] = priorAttrs[attr.Name]
+		}
+	}
+	return tftypes.NewValue(config.Type(), proposed)
+}
+
+func hasErrors(diags []*tfprotov6.Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the summaries of the error diagnostics.
+func errorSummaries(diags []*tfprotov6.Diagnostic) []string {
+	summaries := []string{}
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			summaries = append(summaries, d.Summary)
+		}
+	}
+	return summaries
+}
+
+func requireNoErrors(t *testing.T, diags []*tfprotov6.Diagnostic) {
+	t.Helper()
+	for _, d := range diags {
+		require.NotEqual(t, tfprotov6.DiagnosticSeverityError, d.Severity, "%s: %s", d.Summary, d.Detail)
+	}
+}
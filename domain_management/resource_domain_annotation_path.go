@@ -0,0 +1,570 @@
+package domain_management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/myklst/terraform-provider-st-domain-management/api"
+	"github.com/myklst/terraform-provider-st-domain-management/domain_management/internal"
+	"github.com/myklst/terraform-provider-st-domain-management/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"gomodules.xyz/jsonpatch/v2"
+)
+
+var _ resource.ResourceWithModifyPlan = &domainAnnotationPathResource{}
+var _ resource.ResourceWithImportState = &domainAnnotationPathResource{}
+
+func NewDomainAnnotationPathResource() resource.Resource {
+	return &domainAnnotationPathResource{}
+}
+
+type domainAnnotationPathResourceModel struct {
+	Domain     types.String         `tfsdk:"domain"`
+	Path       types.String         `tfsdk:"path"`
+	Value      jsontypes.Normalized `tfsdk:"value"`
+	OnConflict types.String         `tfsdk:"on_conflict"`
+}
+
+type domainAnnotationPathResource struct {
+	client            *api.Client
+	keyPolicy         *utils.KeyPolicy
+	annotationSchemas *utils.AnnotationSchemas
+	policy            *utils.Policy
+}
+
+func (r *domainAnnotationPathResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_domain_annotation_path"
+}
+
+func (r *domainAnnotationPathResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*ProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = providerData.Client
+	r.keyPolicy = providerData.KeyPolicy
+	r.annotationSchemas = providerData.AnnotationSchemas
+	r.policy = providerData.Policy
+}
+
+func (r *domainAnnotationPathResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: strings.Join([]string{
+			"Manage a single value nested inside a domain's root annotation key using Terraform.",
+			"Only the value at the path is read and written, so several modules can each own a part of a shared root key.",
+			"Do not manage the same root key with `st-domain-management_domain_annotations`.",
+		}, " "),
+		Attributes: map[string]schema.Attribute{
+			"domain": schema.StringAttribute{
+				Description: "The domain name to add the annotation value",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"path": schema.StringAttribute{
+				Description: "JSON Pointer (RFC 6901) to the managed value, starting with the root key, " +
+					"e.g. `/common~1devops/team_a`. `/` inside keys is escaped as `~1` and `~` as `~0`.",
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					utils.NestedAnnotationPath{},
+				},
+			},
+			"value": schema.StringAttribute{
+				CustomType:  jsontypes.NormalizedType{},
+				Description: "JSON formatted value to record at the path. Suitable to use with terraform's built in jsonencode() function.",
+				Required:    true,
+				Validators: []validator.String{
+					utils.AnnotationValueDataTypeRules{},
+				},
+			},
+			"on_conflict": schema.StringAttribute{
+				Description: strings.Join([]string{
+					"What to do on create when a value already exists at the path. Defaults to `error`.",
+					"  - `error` - Fail.",
+					"  - `adopt_if_equal` - Take over the existing value if it is equal to the configured value, fail otherwise.",
+					"  - `overwrite` - Replace the existing value with the configured value.",
+				}, "\n"),
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(onConflictError),
+				Validators: []validator.String{
+					stringvalidator.OneOf(onConflictError, onConflictAdoptIfEqual, onConflictOverwrite),
+				},
+			},
+		},
+	}
+}
+
+func (r *domainAnnotationPathResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check when the resource is being destroyed.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan domainAnnotationPathResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || plan.Path.IsUnknown() {
+		return
+	}
+
+	tokens, err := utils.ParseJSONPointer(plan.Path.ValueString())
+	if err != nil || len(tokens) == 0 {
+		// Reported by the path validator.
+		return
+	}
+
+	if tokens[0] == internal.OwnersAnnotationKey {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("path"),
+			fmt.Sprintf("Annotation key %q is reserved", internal.OwnersAnnotationKey),
+			"The key is used by the provider to record the owner of every root key.",
+		)
+	}
+	resp.Diagnostics.Append(checkKeyPolicy(r.keyPolicy, path.Root("path"), tokens[:1])...)
+	if resp.Diagnostics.HasError() || plan.Domain.IsUnknown() || plan.Value.IsUnknown() {
+		return
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(plan.Value.ValueString()), &value); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("value"), "JSON Unmarshal Error", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+
+	domain := plan.Domain.ValueString()
+	root, rootFound, owners, _, err := r.readRoot(ctx, domain, tokens[0])
+	if errors.Is(err, api.ErrDomainNotFound) {
+		// Reported by apply.
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read annotations of %s to check the value, got error: %s", domain, err))
+		return
+	}
+
+	resp.Diagnostics.Append(checkRootOwner(owners, tokens[0])...)
+
+	doc := map[string]interface{}{}
+	if rootFound {
+		doc[tokens[0]] = utils.CloneJSON(root)
+	}
+	if req.State.Raw.IsNull() {
+		existing, found := utils.GetJSONPointer(doc, tokens)
+		resp.Diagnostics.Append(checkExistingValue(plan.OnConflict.ValueString(), plan.Path.ValueString(), existing, found, value)...)
+	}
+
+	// The checks run against the root key as it will be once the value is
+	// written.
+	if err := utils.SetJSONPointer(doc, tokens, value); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "Invalid JSON Pointer", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(checkAnnotationSchemas(r.annotationSchemas, path.Root("value"), doc)...)
+	resp.Diagnostics.Append(checkPolicy(ctx, r.client, r.policy, path.Root("value"), domain, doc)...)
+}
+
+// Refuses writing into a root key recorded as owned by a
+// st-domain-management_domain_annotations resource.
+func checkRootOwner(owners internal.Owners, rootKey string) (diags diag.Diagnostics) {
+	for _, key := range owners.Foreign([]string{rootKey}) {
+		diags.AddAttributeError(
+			path.Root("path"),
+			fmt.Sprintf("Annotation key %q is owned by %q", key, owners[key]),
+			"The root key is managed by another owner, so no value can be written into it.",
+		)
+	}
+	return diags
+}
+
+// Resolves a value that already exists at the path on create according to
+// on_conflict. Overwriting is only warned about.
+func checkExistingValue(onConflict string, pointer string, existing interface{}, found bool, value interface{}) (diags diag.Diagnostics) {
+	switch {
+	case !found:
+	case onConflict == onConflictOverwrite:
+		diags.AddAttributeWarning(
+			path.Root("value"),
+			fmt.Sprintf("A value already exists at %s and will be overwritten", pointer),
+			fmt.Sprintf("Existing value: %s", formatJSON(existing)),
+		)
+	case onConflict == onConflictAdoptIfEqual && reflect.DeepEqual(existing, value):
+	case onConflict == onConflictAdoptIfEqual:
+		diags.AddAttributeError(
+			path.Root("value"),
+			fmt.Sprintf("A value already exists at %s with a different value", pointer),
+			fmt.Sprintf("Existing value: %s", formatJSON(existing)),
+		)
+	default:
+		diags.AddAttributeError(
+			path.Root("value"),
+			fmt.Sprintf("A value already exists at %s", pointer),
+			"Set on_conflict to \"adopt_if_equal\" or \"overwrite\" to manage the existing value, or import it.",
+		)
+	}
+	return diags
+}
+
+func (r *domainAnnotationPathResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	tflog.Info(ctx, "[resourceDomainAnnotationPathImport!]")
+
+	domain, pointer, found := strings.Cut(req.ID, ":")
+	if !found || domain == "" || pointer == "" {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Expected <domain>:<path>, e.g. example.com:/common~1devops/team_a, got: %s", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("domain"), domain)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("path"), pointer)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("on_conflict"), onConflictError)...)
+}
+
+func (r *domainAnnotationPathResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	tflog.Info(ctx, "[resourceDomainAnnotationPathCreate!]")
+
+	ctx, cancel := context.WithTimeout(ctx, defaultWriteTimeout)
+	defer cancel()
+
+	var plan domainAnnotationPathResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.setValue(ctx, plan, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *domainAnnotationPathResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	tflog.Info(ctx, "[resourceDomainAnnotationPathRead!]")
+
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+
+	var state domainAnnotationPathResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tokens, err := utils.ParseJSONPointer(state.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid JSON Pointer", err.Error())
+		return
+	}
+
+	root, _, _, _, err := r.readRoot(ctx, state.Domain.ValueString(), tokens[0])
+	if errors.Is(err, api.ErrDomainNotFound) {
+		removeDomainNotFound(ctx, state.Domain.ValueString(), &resp.Diagnostics, &resp.State)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read annotations, got error: %s", err))
+		return
+	}
+
+	value, found := utils.GetJSONPointer(map[string]interface{}{tokens[0]: root}, tokens)
+	if !found {
+		// The value was removed outside of Terraform.
+		tflog.Info(ctx, fmt.Sprintf("Annotation value at %s no longer exists, removing from state", state.Path.ValueString()))
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	jsonStr, err := json.Marshal(value)
+	if err != nil {
+		resp.Diagnostics.AddError("JSON Marshal Error", err.Error())
+		return
+	}
+	state.Value = jsontypes.NewNormalizedValue(string(jsonStr))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
+
+func (r *domainAnnotationPathResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	tflog.Info(ctx, "[resourceDomainAnnotationPathUpdate!]")
+
+	ctx, cancel := context.WithTimeout(ctx, defaultWriteTimeout)
+	defer cancel()
+
+	var plan domainAnnotationPathResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.setValue(ctx, plan, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *domainAnnotationPathResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	tflog.Info(ctx, "[resourceDomainAnnotationPathDelete!]")
+
+	ctx, cancel := context.WithTimeout(ctx, defaultWriteTimeout)
+	defer cancel()
+
+	var state domainAnnotationPathResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tokens, err := utils.ParseJSONPointer(state.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid JSON Pointer", err.Error())
+		return
+	}
+
+	domain := state.Domain.ValueString()
+	unlock, err := r.client.LockDomain(ctx, domain)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to lock annotations of domain, got error: %s", err))
+		return
+	}
+	defer unlock()
+
+	root, rootFound, _, revision, err := r.readRoot(ctx, domain, tokens[0])
+	if errors.Is(err, api.ErrDomainNotFound) {
+		// The value was deleted along with the domain.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read annotations, got error: %s", err))
+		return
+	}
+
+	doc := map[string]interface{}{}
+	if rootFound {
+		doc[tokens[0]] = utils.CloneJSON(root)
+	}
+	if !utils.RemoveJSONPointer(doc, tokens) {
+		// Already removed outside of Terraform.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Empty objects are not allowed, so parents left empty are removed too,
+	// up to and including the root key.
+	for i := len(tokens) - 1; i > 0; i-- {
+		parent, _ := utils.GetJSONPointer(doc, tokens[:i])
+		if object, ok := parent.(map[string]interface{}); !ok || len(object) > 0 {
+			break
+		}
+		utils.RemoveJSONPointer(doc, tokens[:i])
+	}
+
+	newRoot, newRootFound := doc[tokens[0]]
+	if err := r.writeRoot(ctx, domain, tokens, root, rootFound, newRoot, newRootFound, revision); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete annotation value, got error: %s", err))
+		return
+	}
+
+	resp.State.RemoveResource(ctx)
+}
+
+// Writes the planned value at the path, leaving the rest of the root key
+// untouched. Missing intermediate objects are created. The owner of the root
+// key and, on create, an existing value at the path are checked again against
+// what the backend holds once the domain is locked.
+func (r *domainAnnotationPathResource) setValue(ctx context.Context, plan domainAnnotationPathResourceModel, creating bool) (diags diag.Diagnostics) {
+	tokens, err := utils.ParseJSONPointer(plan.Path.ValueString())
+	if err != nil {
+		diags.AddError("Invalid JSON Pointer", err.Error())
+		return diags
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(plan.Value.ValueString()), &value); err != nil {
+		diags.AddError("JSON Unmarshal Error", err.Error())
+		return diags
+	}
+
+	domain := plan.Domain.ValueString()
+	unlock, err := r.client.LockDomain(ctx, domain)
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to lock annotations of domain, got error: %s", err))
+		return diags
+	}
+	defer unlock()
+
+	root, rootFound, owners, revision, err := r.readRoot(ctx, domain, tokens[0])
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read annotations, got error: %s", err))
+		return diags
+	}
+
+	diags.Append(checkRootOwner(owners, tokens[0])...)
+
+	doc := map[string]interface{}{}
+	if rootFound {
+		doc[tokens[0]] = utils.CloneJSON(root)
+	}
+	if creating {
+		existing, found := utils.GetJSONPointer(doc, tokens)
+		// Only the errors are reported again, the warning was shown by plan.
+		for _, d := range checkExistingValue(plan.OnConflict.ValueString(), plan.Path.ValueString(), existing, found, value).Errors() {
+			diags.Append(d)
+		}
+		if !diags.HasError() && found && reflect.DeepEqual(existing, value) {
+			tflog.Info(ctx, fmt.Sprintf("Adopting existing annotation value at %s", plan.Path.ValueString()))
+			return diags
+		}
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	if err := utils.SetJSONPointer(doc, tokens, value); err != nil {
+		diags.AddError("Invalid JSON Pointer", err.Error())
+		return diags
+	}
+
+	if err := r.writeRoot(ctx, domain, tokens, root, rootFound, doc[tokens[0]], true, revision); err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to write annotation value, got error: %s", err))
+	}
+	return diags
+}
+
+// Reads a single root key along with the ownership records, and the revision
+// both were read at.
+func (r *domainAnnotationPathResource) readRoot(ctx context.Context, domain string, rootKey string) (root interface{}, found bool, owners internal.Owners, revision string, err error) {
+	owners, _, existing, revision, err := readOwners(ctx, r.client, domain, rootKey)
+	if err != nil {
+		return nil, false, nil, "", err
+	}
+
+	root, found = existing[rootKey]
+	return root, found, owners, revision, nil
+}
+
+// Writes the new value of the root key holding the value at the path, read at
+// the given revision. Prefers a JSON Patch containing only the changed nested
+// paths, so that values written by others in between are kept. Otherwise the
+// whole root key is written with If-Match, failing instead of overwriting
+// concurrent changes.
+func (r *domainAnnotationPathResource) writeRoot(ctx context.Context, domain string, tokens []string, previous interface{}, previousFound bool, next interface{}, nextFound bool, revision string) error {
+	rootKey := tokens[0]
+	previousDoc := map[string]interface{}{}
+	if previousFound {
+		previousDoc[rootKey] = previous
+	}
+	nextDoc := map[string]interface{}{}
+	if nextFound {
+		nextDoc[rootKey] = next
+	}
+
+	previousBytes, err := json.Marshal(previousDoc)
+	if err != nil {
+		return err
+	}
+	nextBytes, err := json.Marshal(nextDoc)
+	if err != nil {
+		return err
+	}
+
+	if r.client.SupportsJSONPatch() {
+		patch, err := jsonpatch.CreatePatch(previousBytes, nextBytes)
+		if err != nil {
+			return err
+		}
+		if len(patch) == 0 {
+			return nil
+		}
+
+		payload, err := json.Marshal(patch)
+		if err != nil {
+			return err
+		}
+
+		// Creating or removing a parent of the value, up to the root key,
+		// replaces or drops whatever others wrote into it in between, so
+		// such a patch is only applied at the revision read.
+		patchRevision := ""
+		if touchesParents(patch, tokens) {
+			patchRevision = revision
+		}
+
+		httpResp, _, err := r.client.PatchAnnotations(ctx, domain, payload, patchRevision)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, api.ErrJSONPatchUnsupported) {
+			return apiError("unable to patch annotations", httpResp, err)
+		}
+	}
+
+	var httpResp []byte
+	switch {
+	case !previousFound:
+		httpResp, _, err = r.client.CreateAnnotations(ctx, domain, string(nextBytes), revision)
+	case !nextFound:
+		payload, marshalErr := json.Marshal([]string{rootKey})
+		if marshalErr != nil {
+			return marshalErr
+		}
+		httpResp, _, err = r.client.DeleteAnnotations(ctx, domain, payload, revision)
+	default:
+		httpResp, _, err = r.client.UpdateAnnotations(ctx, domain, nextBytes, revision)
+	}
+	if err != nil {
+		return apiError(fmt.Sprintf("unable to write %s", rootKey), httpResp, err)
+	}
+	return nil
+}
+
+// Whether any operation of the patch targets a parent of the value at the
+// path, rather than the value itself or something nested inside it.
+func touchesParents(patch []jsonpatch.Operation, tokens []string) bool {
+	for _, operation := range patch {
+		opTokens, err := utils.ParseJSONPointer(operation.Path)
+		if err != nil || len(opTokens) < len(tokens) {
+			return true
+		}
+	}
+	return false
+}
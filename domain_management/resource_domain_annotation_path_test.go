@@ -0,0 +1,159 @@
+package domain_management
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/myklst/terraform-provider-st-domain-management/domain_management/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const annotationPathResourceType = "st-domain-management_domain_annotation_path"
+
+func devopsAnnotations() map[string]interface{} {
+	return map[string]interface{}{
+		"common/devops": map[string]interface{}{
+			"team_b": map[string]interface{}{"status": true},
+		},
+	}
+}
+
+func annotationPathConfig(p *testProvider, value string, attrs map[string]tftypes.Value) tftypes.Value {
+	if attrs == nil {
+		attrs = map[string]tftypes.Value{}
+	}
+	attrs["domain"] = stringValue(testDomain)
+	attrs["path"] = stringValue("/common~1devops/team_a")
+	attrs["value"] = stringValue(value)
+	return p.resourceConfig(annotationPathResourceType, attrs)
+}
+
+func TestAnnotationPathCreateAndUpdate(t *testing.T) {
+	p := newTestProvider(t, devopsAnnotations(), nil)
+
+	state, diags := p.apply(annotationPathResourceType, nil, annotationPathConfig(p, `{"status": false}`, nil))
+	requireNoErrors(t, diags)
+	state, diags = p.apply(annotationPathResourceType, state, annotationPathConfig(p, `{"status": true}`, nil))
+	requireNoErrors(t, diags)
+
+	assert.Equal(t, map[string]interface{}{
+		"common/devops": map[string]interface{}{
+			"team_a": map[string]interface{}{"status": true},
+			"team_b": map[string]interface{}{"status": true},
+		},
+	}, p.backend.snapshot())
+	assert.Equal(t, onConflictError, stringAttribute(t, state.value, "on_conflict"))
+}
+
+func TestAnnotationPathUpdateWithJSONPatch(t *testing.T) {
+	p := newTestProvider(t, devopsAnnotations(), map[string]tftypes.Value{
+		"json_patch": tftypes.NewValue(tftypes.Bool, true),
+	})
+	p.backend.jsonPatch = true
+
+	state, diags := p.apply(annotationPathResourceType, nil, annotationPathConfig(p, `{"status": false}`, nil))
+	requireNoErrors(t, diags)
+	_, diags = p.apply(annotationPathResourceType, state, annotationPathConfig(p, `{"status": true}`, nil))
+	requireNoErrors(t, diags)
+
+	assert.Equal(t, []string{
+		"JSON-PATCH common/devops",
+		"JSON-PATCH common/devops",
+	}, p.backend.writes)
+	assert.Equal(t, map[string]interface{}{
+		"common/devops": map[string]interface{}{
+			"team_a": map[string]interface{}{"status": true},
+			"team_b": map[string]interface{}{"status": true},
+		},
+	}, p.backend.snapshot())
+}
+
+func TestAnnotationPathRootOwned(t *testing.T) {
+	annotations := devopsAnnotations()
+	annotations[internal.OwnersAnnotationKey] = map[string]interface{}{"common/devops": "module-a"}
+	p := newTestProvider(t, annotations, nil)
+
+	state, diags := p.apply(annotationPathResourceType, nil, annotationPathConfig(p, `{"status": true}`, nil))
+	assert.Nil(t, state)
+	assert.Equal(t, []string{`Annotation key "common/devops" is owned by "module-a"`}, errorSummaries(diags))
+	assert.Empty(t, p.backend.writes)
+}
+
+func TestAnnotationPathExistingValue(t *testing.T) {
+	existing := func() map[string]interface{} {
+		annotations := devopsAnnotations()
+		annotations["common/devops"].(map[string]interface{})["team_a"] = map[string]interface{}{"status": true}
+		return annotations
+	}
+
+	t.Run(onConflictError, func(t *testing.T) {
+		p := newTestProvider(t, existing(), nil)
+
+		_, diags := p.apply(annotationPathResourceType, nil, annotationPathConfig(p, `{"status": true}`, nil))
+		assert.Equal(t, []string{"A value already exists at /common~1devops/team_a"}, errorSummaries(diags))
+		assert.Empty(t, p.backend.writes)
+	})
+
+	t.Run(onConflictAdoptIfEqual, func(t *testing.T) {
+		p := newTestProvider(t, existing(), nil)
+		attrs := map[string]tftypes.Value{"on_conflict": stringValue(onConflictAdoptIfEqual)}
+
+		_, diags := p.apply(annotationPathResourceType, nil, annotationPathConfig(p, `{"status": false}`, attrs))
+		assert.Equal(t, []string{"A value already exists at /common~1devops/team_a with a different value"}, errorSummaries(diags))
+
+		state, diags := p.apply(annotationPathResourceType, nil, annotationPathConfig(p, `{"status": true}`, attrs))
+		requireNoErrors(t, diags)
+		require.NotNil(t, state)
+		assert.Empty(t, p.backend.writes)
+	})
+
+	t.Run(onConflictOverwrite, func(t *testing.T) {
+		p := newTestProvider(t, existing(), nil)
+		attrs := map[string]tftypes.Value{"on_conflict": stringValue(onConflictOverwrite)}
+
+		_, diags := p.apply(annotationPathResourceType, nil, annotationPathConfig(p, `{"status": false}`, attrs))
+		requireNoErrors(t, diags)
+		assert.Contains(t, warningSummaries(diags), "A value already exists at /common~1devops/team_a and will be overwritten")
+		assert.Equal(t, []string{"PATCH common/devops"}, p.backend.writes)
+	})
+}
+
+func TestAnnotationPathAnnotationSchemas(t *testing.T) {
+	p := newTestProvider(t, devopsAnnotations(), map[string]tftypes.Value{
+		"annotation_schemas": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{
+			"common/devops": stringValue(`{
+				"type": "object",
+				"additionalProperties": {"type": "object", "required": ["status"]}
+			}`),
+		}),
+	})
+
+	_, diags := p.apply(annotationPathResourceType, nil, annotationPathConfig(p, `{"owner": "team-a"}`, nil))
+	assert.Equal(t, []string{`Annotation "common/devops" does not match the schema for "common/devops"`}, errorSummaries(diags))
+	assert.Empty(t, p.backend.writes)
+
+	_, diags = p.apply(annotationPathResourceType, nil, annotationPathConfig(p, `{"status": true}`, nil))
+	requireNoErrors(t, diags)
+}
+
+func TestAnnotationPathPolicy(t *testing.T) {
+	p := newTestProvider(t, devopsAnnotations(), nil)
+	// The devops-teams rule allows a single team in common/devops, which
+	// team_b already is.
+	policyType := p.schemas.Provider.ValueType().(tftypes.Object).AttributeTypes["policy"]
+	ruleType := policyType.(tftypes.Object).AttributeTypes["rule"].(tftypes.List).ElementType
+	policy := objectValue(policyType, map[string]tftypes.Value{
+		"rule": tftypes.NewValue(tftypes.List{ElementType: ruleType}, []tftypes.Value{
+			objectValue(ruleType, map[string]tftypes.Value{
+				"name":       stringValue("devops-teams"),
+				"expression": stringValue(`size(annotations["common/devops"]) <= 1`),
+			}),
+		}),
+	})
+	p = newTestProvider(t, devopsAnnotations(), map[string]tftypes.Value{"policy": policy})
+
+	_, diags := p.apply(annotationPathResourceType, nil, annotationPathConfig(p, `{"status": true}`, nil))
+	assert.Equal(t, []string{`Policy rule "devops-teams" violated`}, errorSummaries(diags))
+	assert.Empty(t, p.backend.writes)
+}
@@ -3,40 +3,85 @@ package domain_management
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"maps"
+	"reflect"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/myklst/terraform-provider-st-domain-management/api"
+	"github.com/myklst/terraform-provider-st-domain-management/domain_management/internal"
 	"github.com/myklst/terraform-provider-st-domain-management/utils"
 
-	goPlayground "github.com/go-playground/validator"
 	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
+var _ resource.ResourceWithModifyPlan = &domainAnnotationsResource{}
+
+// Values of on_conflict.
+const (
+	onConflictError        = "error"
+	onConflictAdoptIfEqual = "adopt_if_equal"
+	onConflictOverwrite    = "overwrite"
+)
+
+// Default timeouts of the operations, used unless set in the timeouts block.
+const (
+	defaultWriteTimeout = 10 * time.Minute
+	defaultReadTimeout  = 5 * time.Minute
+)
+
 func NewDomainAnnotationResource() resource.Resource {
 	return &domainAnnotationsResource{}
 }
 
 type domainAnnotationResourceModel struct {
-	Domain      types.String         `tfsdk:"domain"`
-	Annotations jsontypes.Normalized `tfsdk:"annotations"`
+	Domain               types.String         `tfsdk:"domain"`
+	Annotations          jsontypes.Normalized `tfsdk:"annotations"`
+	AnnotationsValue     utils.JSONDynamic    `tfsdk:"annotations_value"`
+	SensitiveAnnotations jsontypes.Normalized `tfsdk:"sensitive_annotations"`
+	AnnotationsWO        jsontypes.Normalized `tfsdk:"annotations_wo"`
+	AnnotationsWOVersion types.Int64          `tfsdk:"annotations_wo_version"`
+	Owner                types.String         `tfsdk:"owner"`
+	ForceTakeover        types.Bool           `tfsdk:"force_takeover"`
+	OnConflict           types.String         `tfsdk:"on_conflict"`
+	ManagedPrefix        types.String         `tfsdk:"managed_prefix"`
+	Revision             types.String         `tfsdk:"revision"`
+	PlannedOperations    types.List           `tfsdk:"planned_operations"`
+	DriftedKeys          types.Map            `tfsdk:"drifted_keys"`
+	Timeouts             timeouts.Value       `tfsdk:"timeouts"`
 }
 
 type domainAnnotationsResource struct {
-	client *api.Client
+	client            *api.Client
+	keyPolicy         *utils.KeyPolicy
+	annotationSchemas *utils.AnnotationSchemas
+	policy            *utils.Policy
+	validateOnPlan    bool
 }
 
 func (r *domainAnnotationsResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_domain_annotations"
+	// The root keys are part of the identity and change along with the
+	// annotations.
+	resp.ResourceBehavior.MutableIdentity = true
 }
 
 func (r *domainAnnotationsResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
@@ -44,18 +89,22 @@ func (r *domainAnnotationsResource) Configure(ctx context.Context, req resource.
 		return
 	}
 
-	client, ok := req.ProviderData.(*api.Client)
+	providerData, ok := req.ProviderData.(*ProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *api.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = providerData.Client
+	r.keyPolicy = providerData.KeyPolicy
+	r.annotationSchemas = providerData.AnnotationSchemas
+	r.policy = providerData.Policy
+	r.validateOnPlan = providerData.ValidateOnPlan
 }
 
 func (r *domainAnnotationsResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
@@ -70,62 +119,402 @@ func (r *domainAnnotationsResource) Schema(ctx context.Context, req resource.Sch
 				},
 			},
 			"annotations": schema.StringAttribute{
-				CustomType:  jsontypes.NormalizedType{},
-				Description: "JSON formatted string of key value pairs to record to this domain. Suitable to use with terraform's built in jsonencode() function.",
-				Required:    true,
+				CustomType: jsontypes.NormalizedType{},
+				Description: "JSON formatted string of key value pairs to record to this domain. Suitable to use with terraform's built in jsonencode() function. " +
+					"Conflicts with annotations_value. At least one of annotations, annotations_value, sensitive_annotations and annotations_wo must be set.",
+				Optional: true,
+				Validators: []validator.String{
+					utils.MustBeMapOfString{},
+					utils.AnnotationsDataTypeRules{},
+					stringvalidator.ConflictsWith(path.MatchRoot("annotations_value")),
+					stringvalidator.AtLeastOneOf(
+						path.MatchRoot("annotations_value"),
+						path.MatchRoot("sensitive_annotations"),
+						path.MatchRoot("annotations_wo"),
+					),
+				},
+			},
+			"annotations_value": schema.DynamicAttribute{
+				CustomType: utils.JSONDynamicType{},
+				Description: "Key value pairs to record to this domain, like annotations, but written as a native Terraform object " +
+					"instead of a jsonencode() string. Lists and tuples with the same elements are equal.",
+				Optional: true,
+				Validators: []validator.Dynamic{
+					utils.AnnotationsValueRules{},
+				},
+			},
+			"sensitive_annotations": schema.StringAttribute{
+				CustomType: jsontypes.NormalizedType{},
+				Description: "JSON formatted string of key value pairs to record to this domain, like annotations, " +
+					"but hidden from plan output. Every root key must be set in only one of annotations and sensitive_annotations.",
+				Optional:  true,
+				Sensitive: true,
+				Validators: []validator.String{
+					utils.MustBeMapOfString{},
+					utils.AnnotationsDataTypeRules{},
+				},
+			},
+			"annotations_wo": schema.StringAttribute{
+				CustomType: jsontypes.NormalizedType{},
+				Description: "JSON formatted string of key value pairs to record to this domain, like annotations, " +
+					"but write-only: the values are never stored in plan or state. After create, they are only written again " +
+					"when annotations_wo_version changes. Requires Terraform v1.11 or later.",
+				Optional:  true,
+				WriteOnly: true,
 				Validators: []validator.String{
 					utils.MustBeMapOfString{},
+					utils.AnnotationsDataTypeRules{},
+				},
+			},
+			"annotations_wo_version": schema.Int64Attribute{
+				Description: "Change this to write annotations_wo again.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AlsoRequires(path.MatchRoot("annotations_wo")),
+				},
+			},
+			"owner": schema.StringAttribute{
+				Description: "Identifies who manages these annotations, e.g. a workspace or module ID. " +
+					"The owner of every root key is recorded on the domain under the reserved \"" + internal.OwnersAnnotationKey + "\" key. " +
+					"Root keys owned by someone else are never created, updated or deleted, unless force_takeover is set.",
+				Optional: true,
+			},
+			"force_takeover": schema.BoolAttribute{
+				Description: "Allow creating, updating and deleting root keys owned by someone else, taking over their ownership.",
+				Optional:    true,
+			},
+			"on_conflict": schema.StringAttribute{
+				Description: strings.Join([]string{
+					"What to do on create when a root key already exists on the domain. Defaults to `error`.",
+					"  - `error` - Fail.",
+					"  - `adopt_if_equal` - Take over the existing key if its value is equal to the configured value, fail otherwise.",
+					"  - `overwrite` - Replace the existing value with the configured value.",
+				}, "\n"),
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(onConflictError),
+				Validators: []validator.String{
+					stringvalidator.OneOf(onConflictError, onConflictAdoptIfEqual, onConflictOverwrite),
+				},
+			},
+			"managed_prefix": schema.StringAttribute{
+				Description: "Manage every root key starting with this prefix, e.g. `team_a/`. " +
+					"Root keys under the prefix that are not in annotations are reported as drift on refresh and deleted on apply.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"revision": schema.StringAttribute{
+				Description: "Revision (ETag) of the domain's annotations as of the last read or write. " +
+					"It changes with every write to the domain, including writes by other resources.",
+				Computed: true,
+			},
+			"drifted_keys": schema.MapAttribute{
+				Description: "Root keys found changed outside Terraform by the most recent refresh, mapped to how they changed: " +
+					"`removed`, `value_changed` or `type_changed`. Every refresh replaces the list. An apply that writes the annotations back empties it, " +
+					"while after `terraform apply -refresh-only` the list stays in state until the next refresh clears it.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+			"planned_operations": schema.ListNestedAttribute{
+				Description: strings.Join([]string{
+					"The root key operations of the most recent change of annotations, grouped by operation.",
+					"Each element contains the following attributes:",
+					"  - `operation` - One of `create`, `update` or `delete`.",
+					"  - `key` - The root key.",
+					"  - `paths` - JSON Pointers of the nested values changed by an update, e.g. `/common~1devops/status`. Empty if the whole value is replaced.",
+				}, "\n"),
+				Computed: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"operation": schema.StringAttribute{Computed: true},
+						"key":       schema.StringAttribute{Computed: true},
+						"paths": schema.ListAttribute{
+							ElementType: types.StringType,
+							Computed:    true,
+						},
+					},
 				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
+func (r *domainAnnotationsResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check when the resource is being destroyed.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan domainAnnotationResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The checks against the backend only read, or write dry runs.
+	readTimeout, diags := plan.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	planned, diags := plan.allAnnotations(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || planned.IsUnknown() {
+		return
+	}
+
+	// annotations and sensitive_annotations are both null if only
+	// annotations_wo is set.
+	planObj := map[string]interface{}{}
+	if !planned.IsNull() {
+		resp.Diagnostics.Append(planned.Unmarshal(&planObj)...)
+	}
+	sensitive, diags := plan.sensitiveKeys()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if _, found := planObj[internal.OwnersAnnotationKey]; found {
+		resp.Diagnostics.AddAttributeError(
+			sensitive.path(internal.OwnersAnnotationKey),
+			fmt.Sprintf("Annotation key %q is reserved", internal.OwnersAnnotationKey),
+			"The key is used by the provider to record the owner of every root key. Use the owner attribute instead.",
+		)
+	}
+
+	plainObj, sensitiveObj := sensitive.split(planObj)
+	for _, attribute := range []struct {
+		path path.Path
+		obj  map[string]interface{}
+	}{
+		{plan.plainAnnotationsPath(), plainObj},
+		{path.Root("sensitive_annotations"), sensitiveObj},
+	} {
+		resp.Diagnostics.Append(checkKeyPolicy(r.keyPolicy, attribute.path, slices.Sorted(maps.Keys(attribute.obj)))...)
+		resp.Diagnostics.Append(checkAnnotationSchemas(r.annotationSchemas, attribute.path, attribute.obj)...)
+	}
+
+	writeOnlyObj, diags := writeOnlyAnnotations(ctx, req.Config)
+	resp.Diagnostics.Append(diags...)
+	if _, found := writeOnlyObj[internal.OwnersAnnotationKey]; found {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("annotations_wo"),
+			fmt.Sprintf("Annotation key %q is reserved", internal.OwnersAnnotationKey),
+			"The key is used by the provider to record the owner of every root key. Use the owner attribute instead.",
+		)
+	}
+	resp.Diagnostics.Append(checkKeyPolicy(r.keyPolicy, path.Root("annotations_wo"), slices.Sorted(maps.Keys(writeOnlyObj)))...)
+	resp.Diagnostics.Append(checkAnnotationSchemas(r.annotationSchemas, path.Root("annotations_wo"), writeOnlyObj)...)
+	resp.Diagnostics.Append(mergeWriteOnlyAnnotations(maps.Clone(planObj), writeOnlyObj)...)
+	sensitive.setWriteOnly(maps.Keys(writeOnlyObj))
+	if resp.Diagnostics.HasError() || plan.Domain.IsUnknown() {
+		return
+	}
+
+	resp.Diagnostics.Append(checkPolicy(ctx, r.client, r.policy, plan.plainAnnotationsPath(), plan.Domain.ValueString(), planObj)...)
+
+	stateObj := map[string]interface{}{}
+	creating := req.State.Raw.IsNull()
+	state := domainAnnotationResourceModel{
+		Annotations:          jsontypes.NewNormalizedNull(),
+		AnnotationsValue:     utils.NewJSONDynamicNull(),
+		SensitiveAnnotations: jsontypes.NewNormalizedNull(),
+		PlannedOperations:    types.ListNull(plannedOperationType),
+		DriftedKeys:          types.MapNull(types.StringType),
+	}
+	if !creating {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	prior, diags := state.allAnnotations(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A changed domain replaces the resource, creating every key anew.
+	if !creating && !state.Domain.Equal(plan.Domain) {
+		creating = true
+		prior = jsontypes.NewNormalizedNull()
+	} else if !prior.IsNull() {
+		resp.Diagnostics.Append(prior.Unmarshal(&stateObj)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Summarise the backend operations, since the plan itself only shows the
+	// annotations as a single JSON string.
+	operations, plannedOps, diags := plannedOperations(ctx, prior, planned, state.PlannedOperations)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("planned_operations"), plannedOps)...)
+	// Applying the annotations resolves any drift.
+	if operations != nil {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("drifted_keys"), noDrift())...)
+	} else if !state.DriftedKeys.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("drifted_keys"), state.DriftedKeys)...)
+	}
+	if len(operations) > 0 {
+		resp.Diagnostics.AddWarning(
+			fmt.Sprintf("Planned annotation operations for %s", plan.Domain.ValueString()),
+			plannedOperationsSummary(operations),
+		)
+	}
+
+	existing, diags := r.checkConflicts(ctx, plan.Domain.ValueString(), plan.OnConflict.ValueString(), stateObj, planObj, sensitive, creating)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || !r.validateOnPlan {
+		return
+	}
+
+	// Write-only root keys are only written on create and when
+	// annotations_wo_version changes.
+	writeOnly := plannedWriteOnly{}
+	if creating || !plan.AnnotationsWOVersion.Equal(state.AnnotationsWOVersion) {
+		writeOnly.values = writeOnlyObj
+	}
+	if !creating && writeOnly.values != nil {
+		writeOnly.previous, diags = readWriteOnlyKeys(ctx, req.Private)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(r.dryRun(ctx, plan.Domain.ValueString(), plan.OnConflict.ValueString(), stateObj, planObj, existing, writeOnly, sensitive, creating)...)
+}
+
 func (r *domainAnnotationsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	tflog.Info(ctx, "[resourceDomainAnnotationImport!]")
 
-	type importStruct struct {
-		Domain string   `tfsdk:"domain" json:"domain" validate:"required,hostname_rfc1123"`
-		Keys   []string `tfsdk:"annotations" json:"annotations" validate:"required,min=1"`
+	// There is no configured timeouts block to take the timeout from yet.
+	ctx, cancel := context.WithTimeout(ctx, defaultReadTimeout)
+	defer cancel()
+
+	var imported *internal.AnnotationsImportID
+	if req.ID == "" && req.Identity != nil {
+		var diags diag.Diagnostics
+		imported, diags = importIDFromIdentity(ctx, req.Identity)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if len(imported.Keys) == 0 {
+			resp.Diagnostics.AddError("Invalid import identity", "The identity must contain at least one root key.")
+			return
+		}
+	} else {
+		var err error
+		imported, err = internal.ParseAnnotationsImportID(req.ID)
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid import ID", err.Error())
+			return
+		}
 	}
 
-	imported := importStruct{}
-	err := json.Unmarshal([]byte(req.ID), &imported)
-	if err != nil {
-		resp.Diagnostics.AddError(err.Error(), "Cannot marshal import request to JSON.")
-		return
+	keys := slices.Clone(imported.Keys)
+
+	// Expand the prefix wildcards into the matching root keys.
+	if len(imported.Prefixes) > 0 {
+		domainResp, err := r.client.GetDomain(ctx, imported.Domain)
+		if errors.Is(err, api.ErrDomainNotFound) {
+			resp.Diagnostics.AddError("Domain not found", fmt.Sprintf("Domain %s does not exist.", imported.Domain))
+			return
+		}
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read domain, got error: %s", err))
+			return
+		}
+
+		matched := []string{}
+		for k := range domainResp.Metadata.Annotations {
+			if k != internal.OwnersAnnotationKey && imported.MatchesPrefix(k) {
+				matched = append(matched, k)
+			}
+		}
+		if len(matched) == 0 {
+			resp.Diagnostics.AddError(
+				"No matching annotations",
+				fmt.Sprintf("Domain %s has no root keys starting with %s.", imported.Domain, strings.Join(imported.Prefixes, " or ")),
+			)
+			return
+		}
+		keys = append(keys, matched...)
 	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
 
-	validate := goPlayground.New()
-	err = validate.Struct(imported)
+	bytes, err := json.Marshal(keys)
 	if err != nil {
-		resp.Diagnostics.AddError("Validation error", err.Error())
+		resp.Diagnostics.AddError(err.Error(), "")
 		return
 	}
 
-	bytes, err := json.Marshal(imported.Keys)
+	annotationsResp, revision, err := r.client.ReadAnnotations(ctx, imported.Domain, bytes)
+	if errors.Is(err, api.ErrDomainNotFound) {
+		resp.Diagnostics.AddError("Domain not found", fmt.Sprintf("Domain %s does not exist.", imported.Domain))
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError(err.Error(), "")
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read annotations, got error: %s", err))
 		return
 	}
 
-	annotationsResp, err := r.client.ReadAnnotations(imported.Domain, bytes)
-	if resp.Diagnostics.HasError() {
-		resp.Diagnostics.AddError(err.Error(), "")
+	missing := []string{}
+	for _, k := range keys {
+		if _, found := annotationsResp[k]; !found {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		resp.Diagnostics.AddError(
+			"Annotations not found",
+			fmt.Sprintf("Domain %s has no root keys %s.", imported.Domain, strings.Join(missing, ", ")),
+		)
 		return
 	}
 
 	jsonStr, err := json.Marshal(annotationsResp)
 	if err != nil {
 		resp.Diagnostics.AddError("JSON Marshal Error", err.Error())
+		return
 	}
 
 	state := domainAnnotationResourceModel{
-		Domain:      types.StringValue(imported.Domain),
-		Annotations: jsontypes.NewNormalizedValue(string(jsonStr)),
+		Domain:               types.StringValue(imported.Domain),
+		Annotations:          jsontypes.NewNormalizedValue(string(jsonStr)),
+		AnnotationsValue:     utils.NewJSONDynamicNull(),
+		SensitiveAnnotations: jsontypes.NewNormalizedNull(),
+		AnnotationsWO:        jsontypes.NewNormalizedNull(),
+		AnnotationsWOVersion: types.Int64Null(),
+		OnConflict:           types.StringValue(onConflictError),
+		Revision:             revisionValue(revision),
+		PlannedOperations:    types.ListNull(plannedOperationType),
+		DriftedKeys:          noDrift(),
+		Timeouts:             timeoutsNull(),
 	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+	resp.Diagnostics.Append(setAnnotationsIdentityKeys(ctx, resp.Identity, imported.Domain, keys)...)
 }
 
 func (r *domainAnnotationsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -138,18 +527,201 @@ func (r *domainAnnotationsResource) Create(ctx context.Context, req resource.Cre
 		return
 	}
 
-	errMsg, err := r.client.CreateAnnotations(plan.Domain.ValueString(), plan.Annotations.ValueString())
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultWriteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
+	planned, diags := plan.allAnnotations(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	planObj := map[string]interface{}{}
+	if !planned.IsNull() {
+		resp.Diagnostics.Append(planned.Unmarshal(&planObj)...)
+	}
+	sensitive, diags := plan.sensitiveKeys()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The write-only root keys are created along with the others.
+	writeOnlyObj, diags := writeOnlyAnnotations(ctx, req.Config)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(mergeWriteOnlyAnnotations(planObj, writeOnlyObj)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	sensitive.setWriteOnly(maps.Keys(writeOnlyObj))
+
+	domain := plan.Domain.ValueString()
+	keys := slices.Sorted(maps.Keys(planObj))
+	// Root keys that must never end up in state, even if the create fails.
+	hidden := slices.Collect(maps.Keys(writeOnlyObj))
+
+	unlock, err := r.client.LockDomain(ctx, domain)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create annotations, got error: %s", utils.Extract(errMsg)))
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to lock annotations of domain, got error: %s", err))
+		return
+	}
+	defer unlock()
+
+	owners, ownersExist, existing, revision, err := readOwners(ctx, r.client, domain, keys...)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read annotation owners, got error: %s", err))
+		return
+	}
+	resp.Diagnostics.Append(checkOwnership(owners, keys, sensitive.path, plan.ForceTakeover, plan.Owner)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Root keys that already exist are resolved according to on_conflict,
+	// the rest are created.
+	creationPayload := map[string]any{}
+	updatePayload := map[string]any{}
+	for _, k := range keys {
+		existingValue, found := existing[k]
+		switch {
+		case !found:
+			creationPayload[k] = planObj[k]
+		case plan.OnConflict.ValueString() == onConflictOverwrite:
+			updatePayload[k] = planObj[k]
+		case plan.OnConflict.ValueString() == onConflictAdoptIfEqual && reflect.DeepEqual(existingValue, planObj[k]):
+			tflog.Info(ctx, fmt.Sprintf("Adopting existing annotation %s", k))
+		case plan.OnConflict.ValueString() == onConflictAdoptIfEqual:
+			resp.Diagnostics.AddAttributeError(
+				sensitive.path(k),
+				fmt.Sprintf("Annotation key %q already exists with a different value", k),
+				"on_conflict is adopt_if_equal, so only keys whose existing value is equal to the configured value are adopted. "+
+					"Set on_conflict = \"overwrite\" to replace the existing value.",
+			)
+		default:
+			resp.Diagnostics.AddAttributeError(
+				sensitive.path(k),
+				fmt.Sprintf("Annotation key %q already exists", k),
+				"Set on_conflict to \"adopt_if_equal\" or \"overwrite\" to manage the existing key, or import it.",
+			)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tx := newAnnotationsTransaction(ctx, r.client, domain, withOwners(existing, owners, ownersExist), revision)
+	if len(creationPayload) > 0 {
+		if err := tx.Create(creationPayload); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create annotations, got error: %s", err))
+			return
+		}
+	}
+
+	if len(updatePayload) > 0 {
+		if err := tx.Update(updatePayload); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to overwrite existing annotations, got error: %s", err))
+			r.rollbackCreate(ctx, tx, plan, keys, existing, hidden, resp)
+			return
+		}
+	}
+
+	// Root keys under the managed prefix that are not in config are deleted.
+	strays, err := r.readStrayAnnotations(ctx, domain, plan.ManagedPrefix.ValueString(), planObj)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read annotations under the managed prefix, got error: %s", err))
+		r.rollbackCreate(ctx, tx, plan, keys, existing, hidden, resp)
+		return
+	}
+	strayKeys := slices.Sorted(maps.Keys(strays))
+	resp.Diagnostics.Append(checkOwnership(owners, strayKeys, func(string) path.Path {
+		return path.Root("managed_prefix")
+	}, plan.ForceTakeover, plan.Owner)...)
+	if resp.Diagnostics.HasError() {
+		r.rollbackCreate(ctx, tx, plan, keys, existing, hidden, resp)
+		return
+	}
+	if len(strayKeys) > 0 {
+		maps.Copy(tx.Current, strays)
+		if err := tx.Delete(strayKeys); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete annotations under the managed prefix, got error: %s", err))
+			r.rollbackCreate(ctx, tx, plan, keys, existing, hidden, resp)
+			return
+		}
+	}
+
+	if err := tx.SetOwners(owners, owners.Release(strayKeys).Assign(keys, plan.Owner.ValueString()), ownersExist); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to record annotation owners, got error: %s", err))
+		r.rollbackCreate(ctx, tx, plan, keys, existing, hidden, resp)
 		return
 	}
 
 	state := plan
+	state.Revision = revisionValue(tx.Revision)
+	_, plannedOps, diags := plannedOperations(ctx, jsontypes.NewNormalizedNull(), planned, types.ListNull(plannedOperationType))
+	resp.Diagnostics.Append(diags...)
+	state.PlannedOperations = plannedOps
+	state.DriftedKeys = noDrift()
 	setStateDiags := resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(setStateDiags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	resp.Diagnostics.Append(setWriteOnlyKeys(ctx, resp.Private, slices.Collect(maps.Keys(writeOnlyObj)))...)
+	resp.Diagnostics.Append(setAnnotationsIdentity(ctx, resp.Identity, state.Domain, planned)...)
+}
+
+// Reverts the steps of a failed create. If that fails too, the root keys the
+// create changed and could not revert are written to state, so that they are
+// managed instead of orphaned. The hidden write-only root keys are only
+// recorded in private state, to be deleted along with the others.
+func (r *domainAnnotationsResource) rollbackCreate(ctx context.Context, tx *annotationsTransaction, plan domainAnnotationResourceModel, keys []string, existing map[string]interface{}, hidden []string, resp *resource.CreateResponse) {
+	rollbackErr := tx.Rollback()
+	if rollbackErr == nil {
+		return
+	}
+	resp.Diagnostics.AddError(
+		"Create Annotation: Rollback Error",
+		fmt.Sprintf("Unable to revert the changes already applied, state reflects the partially applied create: %s", rollbackErr),
+	)
+
+	annotations := map[string]interface{}{}
+	hiddenKeys := []string{}
+	current := tx.Annotations()
+	for _, k := range keys {
+		value, found := current[k]
+		if !found {
+			continue
+		}
+		if previous, existed := existing[k]; existed && reflect.DeepEqual(previous, value) {
+			continue
+		}
+		if slices.Contains(hidden, k) {
+			hiddenKeys = append(hiddenKeys, k)
+		} else {
+			annotations[k] = value
+		}
+	}
+	if len(annotations) == 0 && len(hiddenKeys) == 0 {
+		return
+	}
+
+	state := plan
+	diags := state.setAllAnnotations(annotations)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	state.Revision = revisionValue(tx.Revision)
+	state.PlannedOperations = types.ListNull(plannedOperationType)
+	state.DriftedKeys = noDrift()
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+	resp.Diagnostics.Append(setWriteOnlyKeys(ctx, resp.Private, hiddenKeys)...)
+	resp.Diagnostics.Append(setAnnotationsIdentityKeys(ctx, resp.Identity, plan.Domain.ValueString(), slices.Collect(maps.Keys(annotations)))...)
 }
 
 func (r *domainAnnotationsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -162,46 +734,142 @@ func (r *domainAnnotationsResource) Read(ctx context.Context, req resource.ReadR
 		return
 	}
 
+	readTimeout, diags := reqState.Timeouts.Read(ctx, defaultReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
 	// If the annotation is removed outside of Terraform
 	// and state refresh is performed, the annotation may be null.
-	// If annotations is indeed null, return early
-	// as there is nothing to do.
-	if reqState.Annotations.IsNull() {
+	// If annotations is indeed null and no prefix is managed, return early
+	// as there is nothing to do, unless the domain itself is gone.
+	current, diags := reqState.allAnnotations(ctx)
+	resp.Diagnostics.Append(diags...)
+	writeOnlyKeys, diags := readWriteOnlyKeys(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if current.IsNull() && len(writeOnlyKeys) == 0 && reqState.ManagedPrefix.IsNull() {
+		if _, err := r.client.GetDomain(ctx, reqState.Domain.ValueString()); errors.Is(err, api.ErrDomainNotFound) {
+			removeDomainNotFound(ctx, reqState.Domain.ValueString(), &resp.Diagnostics, &resp.State)
+		} else if err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read domain, got error: %s", err))
+		}
 		return
 	}
 
+	// The identity is only changed by apply. It is only set here for
+	// resources created before identities were supported.
+	if req.Identity != nil && req.Identity.Raw.IsFullyNull() {
+		resp.Diagnostics.Append(setAnnotationsIdentity(ctx, resp.Identity, reqState.Domain, current)...)
+	}
+
 	annotations := map[string]interface{}{}
-	diags := reqState.Annotations.Unmarshal(&annotations)
+	if !current.IsNull() {
+		diags := current.Unmarshal(&annotations)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Only the existence of the write-only root keys is checked, their values
+	// are never kept.
+	requested := slices.AppendSeq(slices.Clone(writeOnlyKeys), maps.Keys(annotations))
+
+	annotationsResp := map[string]interface{}{}
+	var revision string
+	if len(requested) > 0 {
+		payload, err := json.Marshal(requested)
+		if err != nil {
+			resp.Diagnostics.Append(diag.NewErrorDiagnostic("Unmarshal Error", err.Error()))
+			return
+		}
+
+		annotationsResp, revision, err = r.client.ReadAnnotations(ctx, reqState.Domain.ValueString(), payload)
+		if errors.Is(err, api.ErrDomainNotFound) {
+			removeDomainNotFound(ctx, reqState.Domain.ValueString(), &resp.Diagnostics, &resp.State)
+			return
+		}
+		if err != nil {
+			resp.Diagnostics.Append(diag.NewErrorDiagnostic("Unmarshal Error", err.Error()))
+			return
+		}
+		if annotationsResp == nil {
+			annotationsResp = map[string]interface{}{}
+		}
+	}
+
+	drift := map[string]string{}
+	for k, kind := range utils.DetectDrift(annotations, annotationsResp) {
+		drift[k] = string(kind)
+	}
+	managed := maps.Clone(annotations)
+	removedWriteOnly := []string{}
+	for _, k := range writeOnlyKeys {
+		if _, found := annotationsResp[k]; !found {
+			drift[k] = string(utils.DriftRemoved)
+			removedWriteOnly = append(removedWriteOnly, k)
+		}
+		delete(annotationsResp, k)
+		managed[k] = nil
+	}
+	if len(removedWriteOnly) > 0 {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("annotations_wo_version"),
+			fmt.Sprintf("Write-only annotations of %s removed outside Terraform", reqState.Domain.ValueString()),
+			fmt.Sprintf("Change annotations_wo_version to write the following root keys again: %s", strings.Join(removedWriteOnly, ", ")),
+		)
+	}
+	if len(drift) > 0 {
+		lines := []string{}
+		for _, k := range slices.Sorted(maps.Keys(drift)) {
+			lines = append(lines, fmt.Sprintf("  %s: %s", k, drift[k]))
+		}
+		resp.Diagnostics.AddWarning(
+			fmt.Sprintf("Annotations of %s changed outside Terraform", reqState.Domain.ValueString()),
+			strings.Join(lines, "\n"),
+		)
+	}
+	driftedKeys, diags := types.MapValueFrom(ctx, types.StringType, drift)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	payload, err := json.Marshal(slices.Collect(maps.Keys(annotations)))
-	if err != nil {
-		resp.Diagnostics.Append(diag.NewErrorDiagnostic("Unmarshal Error", err.Error()))
+	// Root keys under the managed prefix that are not in state are added to
+	// it, so that the next plan deletes them.
+	strays, err := r.readStrayAnnotations(ctx, reqState.Domain.ValueString(), reqState.ManagedPrefix.ValueString(), managed)
+	if errors.Is(err, api.ErrDomainNotFound) {
+		removeDomainNotFound(ctx, reqState.Domain.ValueString(), &resp.Diagnostics, &resp.State)
 		return
 	}
-
-	annotationsResp, err := r.client.ReadAnnotations(reqState.Domain.ValueString(), payload)
 	if err != nil {
-		resp.Diagnostics.Append(diag.NewErrorDiagnostic("Unmarshal Error", err.Error()))
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read annotations under the managed prefix, got error: %s", err))
 		return
 	}
+	if len(strays) > 0 {
+		resp.Diagnostics.AddWarning(
+			fmt.Sprintf("Unmanaged annotations found under %q", reqState.ManagedPrefix.ValueString()),
+			fmt.Sprintf("The following root keys are not in configuration and will be deleted on the next apply: %s",
+				strings.Join(slices.Sorted(maps.Keys(strays)), ", ")),
+		)
+		maps.Copy(annotationsResp, strays)
+	}
 
-	respState := domainAnnotationResourceModel{
-		Domain: reqState.Domain,
+	respState := reqState
+	respState.DriftedKeys = driftedKeys
+	if revision != "" || len(requested) > 0 {
+		respState.Revision = revisionValue(revision)
 	}
 
-	if len(annotationsResp) == 0 {
-		respState.Annotations = jsontypes.NewNormalizedNull()
-	} else {
-		jsonStr, err := json.Marshal(annotationsResp)
-		if err != nil {
-			resp.Diagnostics.Append(diag.NewErrorDiagnostic(err.Error(), ""))
-			return
-		}
-		respState.Annotations = jsontypes.Normalized{StringValue: types.StringValue(string(jsonStr))}
+	resp.Diagnostics.Append(respState.setAllAnnotations(annotationsResp)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 	setStateDiags := resp.State.Set(ctx, respState)
 	resp.Diagnostics.Append(setStateDiags...)
@@ -227,60 +895,190 @@ func (r *domainAnnotationsResource) Update(ctx context.Context, req resource.Upd
 		return
 	}
 
+	updateTimeout, diags := plan.Timeouts.Update(ctx, defaultWriteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	planned, diags := plan.allAnnotations(ctx)
+	resp.Diagnostics.Append(diags...)
+	prior, diags := state.allAnnotations(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	planObj := map[string]interface{}{}
 	stateObj := map[string]interface{}{}
 
-	diags := plan.Annotations.Unmarshal(&planObj)
+	if !planned.IsNull() {
+		resp.Diagnostics.Append(planned.Unmarshal(&planObj)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !prior.IsNull() {
+		diags = prior.Unmarshal(&stateObj)
+	}
+
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	if !state.Annotations.IsNull() {
-		diags = state.Annotations.Unmarshal(&stateObj)
+	_, plan.PlannedOperations, diags = plannedOperations(ctx, prior, planned, state.PlannedOperations)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	if plan.DriftedKeys.IsUnknown() {
+		plan.DriftedKeys = noDrift()
+	}
+
+	domain := state.Domain.ValueString()
 
+	unlock, err := r.client.LockDomain(ctx, domain)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to lock annotations of domain, got error: %s", err))
+		return
+	}
+	defer unlock()
+
+	// The write-only root keys are not in state, so they are compared with
+	// their values on the backend. The configured values are only written
+	// when annotations_wo_version changes.
+	writeOnlyKeys, diags := readWriteOnlyKeys(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	writeOnlyObj, diags := writeOnlyAnnotations(ctx, req.Config)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	planString := plan.Annotations.ValueString()
+	currentWriteOnly := map[string]interface{}{}
+	if len(writeOnlyKeys) > 0 {
+		payload, err := json.Marshal(writeOnlyKeys)
+		if err != nil {
+			resp.Diagnostics.AddError("JSON Marshal Error", err.Error())
+			return
+		}
+		annotationsResp, _, err := r.client.ReadAnnotations(ctx, domain, payload)
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read write-only annotations, got error: %s", err))
+			return
+		}
+		maps.Copy(currentWriteOnly, annotationsResp)
+	}
+	if plan.AnnotationsWOVersion.Equal(state.AnnotationsWOVersion) {
+		writeOnlyObj = currentWriteOnly
+	}
+	maps.Copy(stateObj, currentWriteOnly)
+	resp.Diagnostics.Append(mergeWriteOnlyAnnotations(planObj, writeOnlyObj)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// Root keys that must never end up in state, even if the update fails.
+	hidden := slices.AppendSeq(slices.Clone(writeOnlyKeys), maps.Keys(writeOnlyObj))
+	sensitive, diags := plan.sensitiveKeys()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	sensitive.setWriteOnly(slices.Values(hidden))
 
-	var stateString string
-	if !state.Annotations.IsNull() {
-		stateString = state.Annotations.ValueString()
-	} else {
-		stateString = string(json.RawMessage(`{}`))
+	planString, err := json.Marshal(planObj)
+	if err != nil {
+		resp.Diagnostics.AddError("JSON Marshal Error", err.Error())
+		return
+	}
+	stateString, err := json.Marshal(stateObj)
+	if err != nil {
+		resp.Diagnostics.AddError("JSON Marshal Error", err.Error())
+		return
 	}
 
 	// Get the diff between plan Annotations and state Annotations
-	updateOp, diffError := utils.JSONDiffToTerraformOperations([]byte(stateString), []byte(planString))
+	updateOp, diffError := utils.JSONDiffToTerraformOperations(stateString, planString)
 	if diffError != nil {
 		resp.Diagnostics.AddError("JSON Diff Error", diffError.Error())
 		return
 	}
 
+	// Every root key written by this update, and every root key whose owner
+	// changes, must not belong to someone else.
+	written := slices.Collect(maps.Keys(updateOp.Create))
+	written = slices.AppendSeq(written, maps.Keys(updateOp.Update))
+	if !plan.Owner.Equal(state.Owner) {
+		written = slices.AppendSeq(written, maps.Keys(planObj))
+	}
+	deleted := slices.Collect(maps.Keys(updateOp.Delete))
+
+	// The revision read during refresh changes with every write to the
+	// domain, including those of other resources. Only the root keys of this
+	// resource must be as they were, and the writes are sent with If-Match on
+	// the revision they were checked at.
+	owners, ownersExist, existing, revision, err := readOwners(ctx, r.client, domain, slices.Collect(maps.Keys(stateObj))...)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read annotation owners, got error: %s", err))
+		return
+	}
+	if err := checkUnchanged(stateObj, existing); err != nil {
+		resp.Diagnostics.AddError("Update Annotation: Conflict", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(checkOwnership(owners, append(written, deleted...), sensitive.path, plan.ForceTakeover, plan.Owner, state.Owner)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	nextOwners := owners.Release(deleted).Assign(written, plan.Owner.ValueString())
+
+	// Prefer sending the whole diff as a single JSON Patch request. The server
+	// applies it atomically and changes nested values in place, instead of
+	// replacing whole root keys.
+	patch := append(updateOp.Patch, ownersPatch(owners, nextOwners, ownersExist)...)
+	if len(patch) > 0 && r.client.SupportsJSONPatch() {
+		payload, err := json.Marshal(patch)
+		if err != nil {
+			resp.Diagnostics.AddError("JSON Marshal Error", err.Error())
+			return
+		}
+
+		httpResp, revision, err := r.client.PatchAnnotations(ctx, domain, payload, revision)
+		if err == nil {
+			plan.Revision = revisionValue(revision)
+			resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+			resp.Diagnostics.Append(setWriteOnlyKeys(ctx, resp.Private, slices.Collect(maps.Keys(writeOnlyObj)))...)
+			resp.Diagnostics.Append(setAnnotationsIdentity(ctx, resp.Identity, plan.Domain, planned)...)
+			return
+		}
+
+		if !errors.Is(err, api.ErrJSONPatchUnsupported) {
+			resp.Diagnostics.AddError("Update Annotation: Patch Error", apiError("unable to patch annotations", httpResp, err).Error())
+			resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+			return
+		}
+
+		tflog.Info(ctx, fmt.Sprintf("JSON Patch is not supported by the server, falling back to separate requests: %s", err))
+	}
+
+	// Apply the changes one step at a time. If a step fails, the steps
+	// already applied are reverted so that the update is all or nothing.
+	tx := newAnnotationsTransaction(ctx, r.client, domain, withOwners(stateObj, owners, ownersExist), revision)
+
 	// handle key creation
 	if len(updateOp.Create) > 0 {
 		creationPayload := map[string]any{}
 		for _, v := range updateOp.Create {
 			creationPayload[v.Path] = planObj[v.Path]
-			stateObj[v.Path] = planObj[v.Path]
 		}
-		payload, err := json.Marshal(creationPayload)
-		if err != nil {
-			resp.Diagnostics.AddError("JSON Marshal Error", err.Error())
+		if err := tx.Create(creationPayload); err != nil {
+			r.rollbackUpdate(ctx, tx, state, hidden, resp, "Update Annotation: Create New Key Error", err)
 			return
 		}
-		httpResp, err := r.client.CreateAnnotations(state.Domain.ValueString(), string(payload))
-		if err != nil {
-			resp.Diagnostics.AddError("Update Annotation: Create New Key Error: ", string(httpResp))
-			return
-		} else {
-			setStateDiags := resp.State.Set(ctx, state)
-			resp.Diagnostics.Append(setStateDiags...)
-		}
 	}
 
 	// handle key deletion
@@ -288,21 +1086,11 @@ func (r *domainAnnotationsResource) Update(ctx context.Context, req resource.Upd
 		deletePayload := []string{}
 		for _, v := range updateOp.Delete {
 			deletePayload = append(deletePayload, v.Path)
-			delete(stateObj, v.Path)
 		}
-		payload, err := json.Marshal(deletePayload)
-		if err != nil {
-			resp.Diagnostics.AddError("JSON Marshal Error", err.Error())
+		if err := tx.Delete(deletePayload); err != nil {
+			r.rollbackUpdate(ctx, tx, state, hidden, resp, "Update Annotation: Delete Key Error", err)
 			return
 		}
-		httpResp, err := r.client.DeleteAnnotations(state.Domain.ValueString(), payload)
-		if err != nil {
-			resp.Diagnostics.AddError("Update Annotation: Delete Key Error: ", string(httpResp))
-			return
-		} else {
-			setStateDiags := resp.State.Set(ctx, state)
-			resp.Diagnostics.Append(setStateDiags...)
-		}
 	}
 
 	// handle key updates
@@ -310,30 +1098,57 @@ func (r *domainAnnotationsResource) Update(ctx context.Context, req resource.Upd
 		updatePayload := map[string]any{}
 		for k := range updateOp.Update {
 			updatePayload[k] = planObj[k]
-			stateObj[k] = planObj[k]
-		}
-		payload, err := json.Marshal(updatePayload)
-		if err != nil {
-			resp.Diagnostics.AddError("JSON Marshal Error", err.Error())
-			return
 		}
-		httpResp, err := r.client.UpdateAnnotations(state.Domain.ValueString(), payload)
-		if err != nil {
-			resp.Diagnostics.AddError("Update Annotation: Update Key Error: ", string(httpResp))
+		if err := tx.Update(updatePayload); err != nil {
+			r.rollbackUpdate(ctx, tx, state, hidden, resp, "Update Annotation: Update Key Error", err)
 			return
-		} else {
-			setStateDiags := resp.State.Set(ctx, state)
-			resp.Diagnostics.Append(setStateDiags...)
 		}
 	}
 
+	// handle ownership records
+	if err := tx.SetOwners(owners, nextOwners, ownersExist); err != nil {
+		r.rollbackUpdate(ctx, tx, state, hidden, resp, "Update Annotation: Record Owners Error", err)
+		return
+	}
+
 	finalState := plan
+	finalState.Revision = revisionValue(tx.Revision)
 
 	setStateDiags := resp.State.Set(ctx, finalState)
 	resp.Diagnostics.Append(setStateDiags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	resp.Diagnostics.Append(setWriteOnlyKeys(ctx, resp.Private, slices.Collect(maps.Keys(writeOnlyObj)))...)
+	resp.Diagnostics.Append(setAnnotationsIdentity(ctx, resp.Identity, finalState.Domain, planned)...)
+}
+
+// Reverts the steps of a failed update and writes what the backend holds
+// afterwards to state. That is the prior state if the rollback succeeds, or
+// the partially applied annotations if it does not, except for the hidden
+// write-only root keys.
+func (r *domainAnnotationsResource) rollbackUpdate(ctx context.Context, tx *annotationsTransaction, state *domainAnnotationResourceModel, hidden []string, resp *resource.UpdateResponse, summary string, err error) {
+	resp.Diagnostics.AddError(summary, err.Error())
+
+	if rollbackErr := tx.Rollback(); rollbackErr != nil {
+		resp.Diagnostics.AddError(
+			"Update Annotation: Rollback Error",
+			fmt.Sprintf("Unable to revert the changes already applied, state reflects the partially applied update: %s", rollbackErr),
+		)
+
+		annotations := tx.Annotations()
+		for _, k := range hidden {
+			delete(annotations, k)
+		}
+		diags := state.setAllAnnotations(annotations)
+		resp.Diagnostics.Append(diags...)
+		if diags.HasError() {
+			return
+		}
+		state.Revision = revisionValue(tx.Revision)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
 }
 
 func (r *domainAnnotationsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -347,30 +1162,124 @@ func (r *domainAnnotationsResource) Delete(ctx context.Context, req resource.Del
 		return
 	}
 
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, defaultWriteTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, deleteTimeout)
+	defer cancel()
+
 	// There is a chance that the annotation may be deleted outside of Terraform
 	// Upon the next refresh cycle, the annotations result may be null.
 	// If it is indeed null, just remove the resource from state.
 	// There is nothing else to do to
-	if state.Annotations.IsNull() {
+	current, diags := state.allAnnotations(ctx)
+	resp.Diagnostics.Append(diags...)
+	writeOnlyKeys, diags := readWriteOnlyKeys(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if current.IsNull() && len(writeOnlyKeys) == 0 {
 		resp.State.RemoveResource(ctx)
 		return
 	}
 
 	stateObj := map[string]interface{}{}
-	diags := state.Annotations.Unmarshal(&stateObj)
+	if !current.IsNull() {
+		diags = current.Unmarshal(&stateObj)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	sensitive, diags := state.sensitiveKeys()
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	sensitive.setWriteOnly(slices.Values(writeOnlyKeys))
 
-	// The payload is a json object with keys and values. For annotation deletion, we only need an array of keys.
-	payload, _ := json.Marshal(slices.Collect(maps.Keys(stateObj)))
+	domain := state.Domain.ValueString()
+	// The write-only root keys are deleted along with the others.
+	keys := slices.Sorted(slices.Values(slices.AppendSeq(slices.Clone(writeOnlyKeys), maps.Keys(stateObj))))
 
-	httpResp, err := r.client.DeleteAnnotations(state.Domain.ValueString(), payload)
+	unlock, err := r.client.LockDomain(ctx, domain)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete annotations for domain, got error %s: %s", err, string(httpResp)))
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to lock annotations of domain, got error: %s", err))
 		return
 	}
+	defer unlock()
+
+	owners, ownersExist, existing, revision, err := readOwners(ctx, r.client, domain, slices.Collect(maps.Keys(stateObj))...)
+	if errors.Is(err, api.ErrDomainNotFound) {
+		// The annotations were deleted along with the domain.
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read annotation owners, got error: %s", err))
+		return
+	}
+	if err := checkUnchanged(stateObj, existing); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete annotations for domain, got error: %s", err))
+		return
+	}
+	resp.Diagnostics.Append(checkOwnership(owners, keys, sensitive.path, state.ForceTakeover, state.Owner)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tx := newAnnotationsTransaction(ctx, r.client, domain, withOwners(stateObj, owners, ownersExist), revision)
+	if err := tx.Delete(keys); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete annotations for domain, got error: %s", err))
+		return
+	}
+
+	// The annotations are gone at this point. Stale ownership records do not
+	// prevent anything, so failing to remove them is only worth a warning.
+	if err := tx.SetOwners(owners, owners.Release(keys), ownersExist); err != nil {
+		resp.Diagnostics.AddWarning("Unable to remove annotation owners", err.Error())
+	}
 
 	resp.State.RemoveResource(ctx)
 }
+
+// Removes a resource whose domain was deleted outside of Terraform from
+// state, so that the next plan creates it again instead of updating
+// annotations of a nonexistent domain.
+func removeDomainNotFound(ctx context.Context, domain string, diags *diag.Diagnostics, state *tfsdk.State) {
+	diags.AddWarning(
+		fmt.Sprintf("Domain %s no longer exists", domain),
+		"The domain was deleted outside of Terraform, so the resource has been removed from state.",
+	)
+	state.RemoveResource(ctx)
+}
+
+// The timeouts block of a resource created without configuration, e.g. by
+// import.
+func timeoutsNull() timeouts.Value {
+	return timeouts.Value{
+		Object: types.ObjectNull(map[string]attr.Type{
+			"create": types.StringType,
+			"read":   types.StringType,
+			"update": types.StringType,
+			"delete": types.StringType,
+		}),
+	}
+}
+
+// Returns an empty drifted_keys value.
+func noDrift() types.Map {
+	return types.MapValueMust(types.StringType, map[string]attr.Value{})
+}
+
+// The revision is null if the server does not send ETags.
+func revisionValue(revision string) types.String {
+	if revision == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(revision)
+}
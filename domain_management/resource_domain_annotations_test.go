@@ -0,0 +1,189 @@
+package domain_management
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stringValue(s string) tftypes.Value {
+	return tftypes.NewValue(tftypes.String, s)
+}
+
+func TestCreateRollbackFailureKeepsPartialState(t *testing.T) {
+	p := newTestProvider(t, map[string]interface{}{"common/b": "old"}, nil)
+	// Overwriting common/b fails after the other keys were created, and so
+	// does deleting them again.
+	p.backend.fail[2] = http.StatusInternalServerError
+	p.backend.fail[3] = http.StatusInternalServerError
+
+	config := p.resourceConfig(annotationsResourceType, map[string]tftypes.Value{
+		"domain":         stringValue(testDomain),
+		"annotations":    stringValue(`{"common/a": "new", "common/b": "new"}`),
+		"annotations_wo": stringValue(`{"secret/token": "hunter2"}`),
+		"on_conflict":    stringValue(onConflictOverwrite),
+	})
+	state, diags := p.apply(annotationsResourceType, nil, config)
+	assert.Contains(t, errorSummaries(diags), "Create Annotation: Rollback Error")
+	assert.Equal(t, []string{
+		"POST common/a,secret/token",
+		"PATCH common/b",
+		"DELETE common/a,secret/token",
+	}, p.backend.writes)
+
+	// The created keys are managed, except for the write-only key, which is
+	// only kept out of state.
+	require.NotNil(t, state)
+	assert.JSONEq(t, `{"common/a": "new"}`, stringAttribute(t, state.value, "annotations"))
+	assert.NotContains(t, state.value.String(), "hunter2")
+
+	// Destroying the resource deletes both, but not the key it failed to
+	// overwrite.
+	state, diags = p.apply(annotationsResourceType, state, tftypes.NewValue(config.Type(), nil))
+	requireNoErrors(t, diags)
+	assert.Nil(t, state)
+	assert.Equal(t, map[string]interface{}{"common/b": "old"}, p.backend.snapshot())
+}
+
+func annotationsConfig(p *testProvider, annotations string, attrs map[string]tftypes.Value) tftypes.Value {
+	if attrs == nil {
+		attrs = map[string]tftypes.Value{}
+	}
+	attrs["domain"] = stringValue(testDomain)
+	attrs["annotations"] = stringValue(annotations)
+	return p.resourceConfig(annotationsResourceType, attrs)
+}
+
+// Returns the summaries of the warning diagnostics.
+func warningSummaries(diags []*tfprotov6.Diagnostic) []string {
+	summaries := []string{}
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityWarning {
+			summaries = append(summaries, d.Summary)
+		}
+	}
+	return summaries
+}
+
+func TestOnConflictAdoptIfEqual(t *testing.T) {
+	p := newTestProvider(t, map[string]interface{}{
+		"common/a": map[string]interface{}{"x": float64(1)},
+	}, nil)
+
+	config := annotationsConfig(p, `{"common/a": {"x": 1}, "common/b": "new"}`, map[string]tftypes.Value{
+		"on_conflict": stringValue(onConflictAdoptIfEqual),
+	})
+	state, diags := p.apply(annotationsResourceType, nil, config)
+	requireNoErrors(t, diags)
+	require.NotNil(t, state)
+	assert.JSONEq(t, `{"common/a": {"x": 1}, "common/b": "new"}`, stringAttribute(t, state.value, "annotations"))
+	for _, write := range p.backend.writes {
+		assert.NotContains(t, write, "common/a", "The adopted key must not be written.")
+	}
+
+	// The adopted key is managed like a created one.
+	_, diags = p.apply(annotationsResourceType, state, tftypes.NewValue(config.Type(), nil))
+	requireNoErrors(t, diags)
+	assert.Empty(t, p.backend.snapshot())
+}
+
+func TestOnConflictAdoptIfEqualDifferentValue(t *testing.T) {
+	p := newTestProvider(t, map[string]interface{}{"common/a": "other"}, nil)
+
+	config := annotationsConfig(p, `{"common/a": "new"}`, map[string]tftypes.Value{
+		"on_conflict": stringValue(onConflictAdoptIfEqual),
+	})
+	state, diags := p.apply(annotationsResourceType, nil, config)
+	assert.Nil(t, state)
+	assert.Equal(t, []string{`Annotation key "common/a" already exists with a different value`}, errorSummaries(diags))
+	assert.Empty(t, p.backend.writes)
+	assert.Equal(t, map[string]interface{}{"common/a": "other"}, p.backend.snapshot())
+}
+
+func TestOnConflictOverwrite(t *testing.T) {
+	p := newTestProvider(t, map[string]interface{}{"common/a": "old"}, nil)
+
+	config := annotationsConfig(p, `{"common/a": "new"}`, map[string]tftypes.Value{
+		"on_conflict": stringValue(onConflictOverwrite),
+	})
+	state, diags := p.apply(annotationsResourceType, nil, config)
+	requireNoErrors(t, diags)
+	require.NotNil(t, state)
+	assert.Contains(t, warningSummaries(diags), `Annotation key "common/a" already exists and will be overwritten`)
+	assert.Contains(t, p.backend.writes, "PATCH common/a")
+	assert.Equal(t, map[string]interface{}{"common/a": "new"}, p.backend.snapshot())
+}
+
+func TestOnConflictError(t *testing.T) {
+	p := newTestProvider(t, map[string]interface{}{"common/a": "old"}, nil)
+
+	state, diags := p.apply(annotationsResourceType, nil, annotationsConfig(p, `{"common/a": "new"}`, nil))
+	assert.Nil(t, state)
+	assert.Equal(t, []string{`Annotation key "common/a" already exists`}, errorSummaries(diags))
+	assert.Empty(t, p.backend.writes)
+}
+
+func TestManagedPrefixDeletesStrayKeys(t *testing.T) {
+	p := newTestProvider(t, map[string]interface{}{
+		"team-a/old": "stray",
+		"team-b/x":   "other team",
+	}, nil)
+
+	config := annotationsConfig(p, `{"team-a/status": "live"}`, map[string]tftypes.Value{
+		"managed_prefix": stringValue("team-a/"),
+	})
+	state, diags := p.apply(annotationsResourceType, nil, config)
+	requireNoErrors(t, diags)
+	require.NotNil(t, state)
+	assert.Contains(t, p.backend.writes, "DELETE team-a/old")
+	assert.Equal(t, map[string]interface{}{
+		"team-a/status": "live",
+		"team-b/x":      "other team",
+	}, p.backend.snapshot())
+
+	// A key created under the prefix outside Terraform is reported on
+	// refresh and deleted by the next apply.
+	p.backend.annotations["team-a/new"] = "stray"
+	state, diags = p.read(annotationsResourceType, state)
+	requireNoErrors(t, diags)
+	require.NotNil(t, state)
+	assert.Contains(t, warningSummaries(diags), `Unmanaged annotations found under "team-a/"`)
+	assert.JSONEq(t, `{"team-a/status": "live", "team-a/new": "stray"}`, stringAttribute(t, state.value, "annotations"))
+
+	state, diags = p.apply(annotationsResourceType, state, config)
+	requireNoErrors(t, diags)
+	require.NotNil(t, state)
+	assert.JSONEq(t, `{"team-a/status": "live"}`, stringAttribute(t, state.value, "annotations"))
+	assert.Equal(t, map[string]interface{}{
+		"team-a/status": "live",
+		"team-b/x":      "other team",
+	}, p.backend.snapshot())
+}
+
+func TestReadRemovesDeletedDomain(t *testing.T) {
+	p := newTestProvider(t, map[string]interface{}{}, nil)
+
+	state, diags := p.apply(annotationsResourceType, nil, annotationsConfig(p, `{"common/a": "a"}`, nil))
+	requireNoErrors(t, diags)
+
+	// A key deleted outside Terraform is drift, not a missing domain.
+	delete(p.backend.annotations, "common/a")
+	refreshed, diags := p.read(annotationsResourceType, state)
+	requireNoErrors(t, diags)
+	require.NotNil(t, refreshed)
+	assert.Equal(t, []string{"Annotations of example.com changed outside Terraform"}, warningSummaries(diags))
+
+	// The missing domain is told apart by the response of the annotations
+	// read alone.
+	p.backend.missing = true
+	p.backend.reads = nil
+	refreshed, diags = p.read(annotationsResourceType, state)
+	requireNoErrors(t, diags)
+	assert.Nil(t, refreshed)
+	assert.Equal(t, []string{"Domain example.com no longer exists"}, warningSummaries(diags))
+	assert.Equal(t, []string{"GET /domains/example.com/annotations"}, p.backend.reads)
+}
@@ -0,0 +1,239 @@
+package domain_management
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+
+	"github.com/myklst/terraform-provider-st-domain-management/utils"
+
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// The attributes holding the root keys of a resource. The values of
+// sensitive_annotations and annotations_wo are never shown in diagnostics.
+type sensitiveKeys struct {
+	// annotations, or annotations_value if it is used instead.
+	plain     path.Path
+	sensitive map[string]struct{}
+	writeOnly map[string]struct{}
+}
+
+// Returns the attribute holding the root key.
+func (s sensitiveKeys) path(key string) path.Path {
+	if _, found := s.sensitive[key]; found {
+		return path.Root("sensitive_annotations")
+	}
+	if _, found := s.writeOnly[key]; found {
+		return path.Root("annotations_wo")
+	}
+	return s.plain
+}
+
+// Returns the attribute holding all of the root keys, if they are held by a
+// single one.
+func (s sensitiveKeys) commonPath(keys []string) (path.Path, bool) {
+	if len(keys) == 0 {
+		return s.plain, true
+	}
+	common := s.path(keys[0])
+	for _, k := range keys[1:] {
+		if !s.path(k).Equal(common) {
+			return path.Empty(), false
+		}
+	}
+	return common, true
+}
+
+// Whether the value of the root key must not be shown.
+func (s sensitiveKeys) hides(key string) bool {
+	_, sensitive := s.sensitive[key]
+	_, writeOnly := s.writeOnly[key]
+	return sensitive || writeOnly
+}
+
+// Marks the root keys as set in annotations_wo.
+func (s sensitiveKeys) setWriteOnly(keys iter.Seq[string]) {
+	for k := range keys {
+		s.writeOnly[k] = struct{}{}
+	}
+}
+
+// Formats the value of the root key for diagnostics.
+func (s sensitiveKeys) format(key string, v interface{}) string {
+	if s.hides(key) {
+		return "(sensitive value)"
+	}
+	return formatJSON(v)
+}
+
+// Splits the root keys into those of annotations and sensitive_annotations.
+func (s sensitiveKeys) split(annotations map[string]interface{}) (plain, sensitive map[string]interface{}) {
+	plain = map[string]interface{}{}
+	sensitive = map[string]interface{}{}
+	for k, v := range annotations {
+		if _, found := s.sensitive[k]; found {
+			sensitive[k] = v
+		} else {
+			plain[k] = v
+		}
+	}
+	return plain, sensitive
+}
+
+// Returns the attributes holding the root keys, with the root keys of
+// sensitive_annotations. The root keys of annotations_wo are only in the
+// configuration, and are marked with setWriteOnly.
+func (m *domainAnnotationResourceModel) sensitiveKeys() (keys sensitiveKeys, diags diag.Diagnostics) {
+	keys = sensitiveKeys{
+		plain:     m.plainAnnotationsPath(),
+		sensitive: map[string]struct{}{},
+		writeOnly: map[string]struct{}{},
+	}
+	if m.SensitiveAnnotations.IsNull() || m.SensitiveAnnotations.IsUnknown() {
+		return keys, diags
+	}
+
+	sensitiveObj := map[string]interface{}{}
+	diags.Append(m.SensitiveAnnotations.Unmarshal(&sensitiveObj)...)
+	for k := range sensitiveObj {
+		keys.sensitive[k] = struct{}{}
+	}
+	return keys, diags
+}
+
+// Returns annotations and sensitive_annotations merged into the single object
+// of root keys held on the domain. The result is null if both are null, and
+// unknown if either is unknown. A root key in both attributes is an error.
+func (m *domainAnnotationResourceModel) allAnnotations(ctx context.Context) (jsontypes.Normalized, diag.Diagnostics) {
+	annotations, diags := m.plainAnnotations(ctx)
+	if diags.HasError() || m.SensitiveAnnotations.IsNull() {
+		return annotations, diags
+	}
+	if annotations.IsUnknown() || m.SensitiveAnnotations.IsUnknown() {
+		return jsontypes.NewNormalizedUnknown(), diags
+	}
+
+	merged := map[string]interface{}{}
+	if !annotations.IsNull() {
+		diags.Append(annotations.Unmarshal(&merged)...)
+	}
+	sensitiveObj := map[string]interface{}{}
+	diags.Append(m.SensitiveAnnotations.Unmarshal(&sensitiveObj)...)
+	if diags.HasError() {
+		return jsontypes.NewNormalizedUnknown(), diags
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(sensitiveObj)) {
+		if _, found := merged[k]; found {
+			diags.AddAttributeError(
+				path.Root("sensitive_annotations"),
+				fmt.Sprintf("Annotation key %q is set in both annotations and sensitive_annotations", k),
+				"Every root key must be set in only one of the two attributes.",
+			)
+			continue
+		}
+		merged[k] = sensitiveObj[k]
+	}
+	if diags.HasError() {
+		return jsontypes.NewNormalizedUnknown(), diags
+	}
+
+	jsonStr, err := json.Marshal(merged)
+	if err != nil {
+		diags.AddError("JSON Marshal Error", err.Error())
+		return jsontypes.NewNormalizedUnknown(), diags
+	}
+	return jsontypes.NewNormalizedValue(string(jsonStr)), diags
+}
+
+// Returns annotations, or annotations_value converted to JSON if it is used
+// instead.
+func (m *domainAnnotationResourceModel) plainAnnotations(ctx context.Context) (jsontypes.Normalized, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if m.AnnotationsValue.IsNull() {
+		return m.Annotations, diags
+	}
+	if !m.AnnotationsValue.IsFullyKnown(ctx) {
+		return jsontypes.NewNormalizedUnknown(), diags
+	}
+
+	jsonStr, err := utils.TFTypesToBytes(m.AnnotationsValue.DynamicValue)
+	if err != nil {
+		diags.AddAttributeError(path.Root("annotations_value"), "JSON Error", fmt.Sprintf("Cannot convert annotations_value to json: %s", err))
+		return jsontypes.NewNormalizedUnknown(), diags
+	}
+	return jsontypes.NewNormalizedValue(string(jsonStr)), diags
+}
+
+// Returns the attribute holding the root keys that are not sensitive.
+func (m *domainAnnotationResourceModel) plainAnnotationsPath() path.Path {
+	if !m.AnnotationsValue.IsNull() {
+		return path.Root("annotations_value")
+	}
+	return path.Root("annotations")
+}
+
+// Splits the root keys held on the domain between annotations, or
+// annotations_value if it is used instead, and sensitive_annotations. Root
+// keys stay in sensitive_annotations if they were there before, every other
+// root key goes to annotations. Either attribute is null if it is left
+// without root keys.
+func (m *domainAnnotationResourceModel) setAllAnnotations(annotations map[string]interface{}) (diags diag.Diagnostics) {
+	sensitive, diags := m.sensitiveKeys()
+	if diags.HasError() {
+		return diags
+	}
+
+	plain, sensitiveObj := sensitive.split(annotations)
+
+	var err error
+	if !m.AnnotationsValue.IsNull() {
+		m.AnnotationsValue, err = dynamicObject(plain)
+	} else {
+		m.Annotations, err = normalizedObject(plain)
+	}
+	if err != nil {
+		diags.AddError("JSON Marshal Error", err.Error())
+		return diags
+	}
+	if m.SensitiveAnnotations, err = normalizedObject(sensitiveObj); err != nil {
+		diags.AddError("JSON Marshal Error", err.Error())
+	}
+	return diags
+}
+
+// Returns the object as a JSON value, or null if it is empty.
+func normalizedObject(obj map[string]interface{}) (jsontypes.Normalized, error) {
+	if len(obj) == 0 {
+		return jsontypes.NewNormalizedNull(), nil
+	}
+
+	jsonStr, err := json.Marshal(obj)
+	if err != nil {
+		return jsontypes.NewNormalizedNull(), err
+	}
+	return jsontypes.NewNormalizedValue(string(jsonStr)), nil
+}
+
+// Returns the object as a native Terraform value, or null if it is empty.
+func dynamicObject(obj map[string]interface{}) (utils.JSONDynamic, error) {
+	if len(obj) == 0 {
+		return utils.NewJSONDynamicNull(), nil
+	}
+
+	jsonStr, err := json.Marshal(obj)
+	if err != nil {
+		return utils.NewJSONDynamicNull(), err
+	}
+	value, err := utils.JSONToTerraformDynamicValue(jsonStr)
+	if err != nil {
+		return utils.NewJSONDynamicNull(), err
+	}
+	return utils.JSONDynamic{DynamicValue: value}, nil
+}
@@ -0,0 +1,71 @@
+package domain_management
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/myklst/terraform-provider-st-domain-management/domain_management/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyInAnnotationsAndSensitiveAnnotations(t *testing.T) {
+	p := newTestProvider(t, map[string]interface{}{}, nil)
+
+	state, diags := p.apply(annotationsResourceType, nil, annotationsConfig(p, `{"common/a": "a", "secret/key": "plain"}`, map[string]tftypes.Value{
+		"sensitive_annotations": stringValue(`{"secret/key": "hunter2"}`),
+	}))
+	assert.Nil(t, state)
+	assert.Equal(t, []string{`Annotation key "secret/key" is set in both annotations and sensitive_annotations`}, errorSummaries(diags))
+	assert.Empty(t, p.backend.writes)
+
+	// Moving a key into both on update is rejected as well.
+	state, diags = p.apply(annotationsResourceType, nil, annotationsConfig(p, `{"common/a": "a"}`, map[string]tftypes.Value{
+		"sensitive_annotations": stringValue(`{"secret/key": "hunter2"}`),
+	}))
+	requireNoErrors(t, diags)
+	writes := len(p.backend.writes)
+
+	_, diags = p.apply(annotationsResourceType, state, annotationsConfig(p, `{"common/a": "a", "secret/key": "plain"}`, map[string]tftypes.Value{
+		"sensitive_annotations": stringValue(`{"secret/key": "hunter2"}`),
+	}))
+	assert.Equal(t, []string{`Annotation key "secret/key" is set in both annotations and sensitive_annotations`}, errorSummaries(diags))
+	assert.Len(t, p.backend.writes, writes)
+}
+
+func TestKeyInAnnotationsAndAnnotationsWO(t *testing.T) {
+	p := newTestProvider(t, map[string]interface{}{}, nil)
+
+	state, diags := p.apply(annotationsResourceType, nil, annotationsConfig(p, `{"secret/key": "plain"}`, map[string]tftypes.Value{
+		"annotations_wo": stringValue(`{"secret/key": "hunter2"}`),
+	}))
+	require.Nil(t, state)
+	assert.Equal(t, []string{`Annotation key "secret/key" is set in both annotations_wo and annotations or sensitive_annotations`}, errorSummaries(diags))
+	assert.Empty(t, p.backend.writes)
+}
+
+func TestOwnedKeyReportedOnItsAttribute(t *testing.T) {
+	annotations := map[string]interface{}{
+		"common/a":                   "a",
+		"secret/key":                 "hunter2",
+		internal.OwnersAnnotationKey: map[string]interface{}{"common/a": "module-a", "secret/key": "module-a"},
+	}
+	p := newTestProvider(t, annotations, nil)
+
+	_, diags := p.apply(annotationsResourceType, nil, annotationsConfig(p, `{"common/a": "b"}`, map[string]tftypes.Value{
+		"sensitive_annotations": stringValue(`{"secret/key": "hunter3"}`),
+		"on_conflict":           stringValue(onConflictOverwrite),
+	}))
+	attributes := map[string]*tftypes.AttributePath{}
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			attributes[d.Summary] = d.Attribute
+		}
+	}
+	assert.Equal(t, map[string]*tftypes.AttributePath{
+		`Annotation key "common/a" is owned by "module-a"`:   tftypes.NewAttributePath().WithAttributeName("annotations"),
+		`Annotation key "secret/key" is owned by "module-a"`: tftypes.NewAttributePath().WithAttributeName("sensitive_annotations"),
+	}, attributes)
+	assert.Empty(t, p.backend.writes)
+}
@@ -0,0 +1,91 @@
+package domain_management
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+)
+
+// The private state key holding the root keys of annotations_wo, since the
+// attribute itself is always null in state.
+const writeOnlyKeysPrivateKey = "annotations_wo_keys"
+
+// The private state of a resource, as passed in requests.
+type privateStateGetter interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+// The private state of a resource, as returned in responses.
+type privateStateSetter interface {
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// Returns the root keys of annotations_wo written by the last apply.
+func readWriteOnlyKeys(ctx context.Context, private privateStateGetter) (keys []string, diags diag.Diagnostics) {
+	if private == nil {
+		return nil, diags
+	}
+
+	value, diags := private.GetKey(ctx, writeOnlyKeysPrivateKey)
+	if diags.HasError() || len(value) == 0 {
+		return nil, diags
+	}
+
+	if err := json.Unmarshal(value, &keys); err != nil {
+		diags.AddError("JSON Unmarshal Error", fmt.Sprintf("Unable to read the root keys of annotations_wo from private state: %s", err))
+	}
+	return keys, diags
+}
+
+// Records the root keys of annotations_wo in private state.
+func setWriteOnlyKeys(ctx context.Context, private privateStateSetter, keys []string) (diags diag.Diagnostics) {
+	if len(keys) == 0 {
+		return private.SetKey(ctx, writeOnlyKeysPrivateKey, nil)
+	}
+
+	value, err := json.Marshal(slices.Sorted(slices.Values(keys)))
+	if err != nil {
+		diags.AddError("JSON Marshal Error", err.Error())
+		return diags
+	}
+	return private.SetKey(ctx, writeOnlyKeysPrivateKey, value)
+}
+
+// Returns annotations_wo from the configuration, the only place its values
+// are available. Empty if it is null or unknown.
+func writeOnlyAnnotations(ctx context.Context, config tfsdk.Config) (map[string]interface{}, diag.Diagnostics) {
+	writeOnlyObj := map[string]interface{}{}
+
+	var writeOnly jsontypes.Normalized
+	diags := config.GetAttribute(ctx, path.Root("annotations_wo"), &writeOnly)
+	if diags.HasError() || writeOnly.IsNull() || writeOnly.IsUnknown() {
+		return writeOnlyObj, diags
+	}
+
+	diags.Append(writeOnly.Unmarshal(&writeOnlyObj)...)
+	return writeOnlyObj, diags
+}
+
+// Adds the root keys of annotations_wo to the annotations. A root key set in
+// both is an error.
+func mergeWriteOnlyAnnotations(annotations, writeOnly map[string]interface{}) (diags diag.Diagnostics) {
+	for _, k := range slices.Sorted(maps.Keys(writeOnly)) {
+		if _, found := annotations[k]; found {
+			diags.AddAttributeError(
+				path.Root("annotations_wo"),
+				fmt.Sprintf("Annotation key %q is set in both annotations_wo and annotations or sensitive_annotations", k),
+				"Every root key must be set in only one of the attributes.",
+			)
+			continue
+		}
+		annotations[k] = writeOnly[k]
+	}
+	return diags
+}
@@ -0,0 +1,74 @@
+package domain_management
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteOnlyAnnotationsOnly(t *testing.T) {
+	p := newTestProvider(t, map[string]interface{}{}, nil)
+
+	config := p.resourceConfig(annotationsResourceType, map[string]tftypes.Value{
+		"domain":         stringValue(testDomain),
+		"annotations_wo": stringValue(`{"secret/token": "hunter2"}`),
+	})
+	state, diags := p.apply(annotationsResourceType, nil, config)
+	requireNoErrors(t, diags)
+	require.NotNil(t, state)
+	assert.Equal(t, map[string]interface{}{"secret/token": "hunter2"}, p.backend.snapshot())
+
+	// The value is written, but never kept in state.
+	assert.NotContains(t, state.value.String(), "hunter2")
+	assert.True(t, attribute(t, state.value, "annotations").IsNull())
+	assert.True(t, attribute(t, state.value, "annotations_wo").IsNull())
+
+	state, diags = p.read(annotationsResourceType, state)
+	requireNoErrors(t, diags)
+	require.NotNil(t, state)
+	assert.NotContains(t, state.value.String(), "hunter2")
+
+	// Applying again changes nothing, and destroy deletes the key.
+	writes := len(p.backend.writes)
+	state, diags = p.apply(annotationsResourceType, state, config)
+	requireNoErrors(t, diags)
+	assert.Len(t, p.backend.writes, writes)
+
+	state, diags = p.apply(annotationsResourceType, state, tftypes.NewValue(config.Type(), nil))
+	requireNoErrors(t, diags)
+	assert.Nil(t, state)
+	assert.Empty(t, p.backend.snapshot())
+}
+
+func TestSensitiveAnnotationsOnly(t *testing.T) {
+	p := newTestProvider(t, map[string]interface{}{}, nil)
+
+	config := p.resourceConfig(annotationsResourceType, map[string]tftypes.Value{
+		"domain":                stringValue(testDomain),
+		"sensitive_annotations": stringValue(`{"secret/key": "hunter2"}`),
+	})
+	state, diags := p.apply(annotationsResourceType, nil, config)
+	requireNoErrors(t, diags)
+	require.NotNil(t, state)
+	assert.Equal(t, map[string]interface{}{"secret/key": "hunter2"}, p.backend.snapshot())
+	assert.True(t, attribute(t, state.value, "annotations").IsNull())
+}
+
+func TestAnnotationAttributeCombinations(t *testing.T) {
+	p := newTestProvider(t, map[string]interface{}{}, nil)
+
+	// At least one of the annotation attributes must be set.
+	config := p.resourceConfig(annotationsResourceType, map[string]tftypes.Value{
+		"domain": stringValue(testDomain),
+	})
+	assert.Equal(t, []string{"Invalid Attribute Combination"}, errorSummaries(p.validate(annotationsResourceType, config)))
+
+	// The two plain forms conflict.
+	config = annotationsConfig(p, `{"common/a": "a"}`, map[string]tftypes.Value{
+		"annotations_value": tftypes.NewValue(tftypes.Object{AttributeTypes: map[string]tftypes.Type{"common/b": tftypes.String}},
+			map[string]tftypes.Value{"common/b": stringValue("b")}),
+	})
+	assert.Contains(t, errorSummaries(p.validate(annotationsResourceType, config)), "Invalid Attribute Combination")
+}
@@ -0,0 +1,8 @@
+ephemeral "st-domain-management_domain_annotations" "challenge" {
+  domain = "example.xyz"
+  keys   = ["common/dns/challenge"]
+}
+
+locals {
+  challenge = jsondecode(ephemeral.st-domain-management_domain_annotations.challenge.annotations)["common/dns/challenge"]
+}
@@ -0,0 +1,13 @@
+list "st-domain-management_domain_annotations" "team_a" {
+  provider         = st-domain-management
+  include_resource = true
+
+  config {
+    domain_labels = {
+      include = {
+        "common/env" = "prod"
+      }
+    }
+    key_prefix = "team-a/"
+  }
+}
@@ -7,5 +7,33 @@ terraform {
 }
 
 provider "st-domain-management" {
-  endpoint = "http://localhost:10800"
+  endpoint         = "http://localhost:10800"
+  validate_on_plan = true
+
+  annotation_schemas = {
+    "common/devops" = jsonencode({
+      type     = "object"
+      required = ["status"]
+      properties = {
+        status = { type = "boolean" }
+      }
+    })
+    "team-dns/*" = "${path.module}/schemas/team-dns.json"
+  }
+
+  key_policy {
+    prefix_pattern     = "common|team-[a-z]+"
+    allowed_characters = "a-z0-9/-"
+    max_length         = 63
+    reserved_prefixes  = ["common/internal"]
+  }
+
+  policy {
+    rule {
+      name       = "prod-devops-status"
+      expression = "!(\"common/env\" in labels && labels[\"common/env\"] == \"prod\" && \"common/devops/status\" in annotations && annotations[\"common/devops/status\"] == false)"
+      message    = "Production domains must not have common/devops/status set to false."
+      severity   = "error"
+    }
+  }
 }
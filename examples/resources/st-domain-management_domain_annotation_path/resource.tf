@@ -0,0 +1,8 @@
+resource "st-domain-management_domain_annotation_path" "example" {
+  domain = "example.xyz"
+  path   = "/common~1devops/team_a"
+  value = jsonencode({
+    status = true
+    owner  = "team-a@example.xyz"
+  })
+}
@@ -0,0 +1,7 @@
+import {
+  to = st-domain-management_domain_annotations.example
+  identity = {
+    domain = "example.xyz"
+    keys   = ["common/devops", "common/env"]
+  }
+}
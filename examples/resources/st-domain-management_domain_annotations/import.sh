@@ -0,0 +1,8 @@
+# Import specific root keys.
+terraform import st-domain-management_domain_annotations.example 'example.xyz:common/devops,common/env'
+
+# Import every root key starting with a prefix.
+terraform import st-domain-management_domain_annotations.example 'example.xyz:top-level/*'
+
+# The JSON form is still accepted.
+terraform import st-domain-management_domain_annotations.example '{"domain":"example.xyz","annotations":["common/devops"]}'
@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// AnnotationSchemas validates annotation values against JSON Schemas. The
+// schemas are selected by matching the root key against a key or a key glob,
+// e.g. "common/*". A nil AnnotationSchemas accepts every value.
+type AnnotationSchemas struct {
+	entries []annotationSchema
+}
+
+type annotationSchema struct {
+	pattern string
+	schema  *jsonschema.Schema
+}
+
+// SchemaViolation is a single JSON Schema validation failure. Path uses the
+// same index notation as DataTypeViolation, e.g. ["common/devops"]["list"][1].
+type SchemaViolation struct {
+	Pattern string
+	Path    string
+	Message string
+}
+
+// Compiles the schemas, keyed by key or key glob. Each schema is either an
+// inline JSON Schema document or a path to a file containing one.
+func NewAnnotationSchemas(schemas map[string]string) (*AnnotationSchemas, error) {
+	compiler := jsonschema.NewCompiler()
+	annotationSchemas := &AnnotationSchemas{}
+
+	for _, pattern := range slices.Sorted(maps.Keys(schemas)) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid key glob %q: %w", pattern, err)
+		}
+
+		location := schemas[pattern]
+		if strings.HasPrefix(strings.TrimSpace(location), "{") {
+			location = "mem://annotation_schemas/" + url.PathEscape(pattern) + ".json"
+			if err := compiler.AddResource(location, strings.NewReader(schemas[pattern])); err != nil {
+				return nil, fmt.Errorf("invalid schema for %q: %w", pattern, err)
+			}
+		}
+
+		schema, err := compiler.Compile(location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema for %q: %w", pattern, err)
+		}
+
+		annotationSchemas.entries = append(annotationSchemas.entries, annotationSchema{
+			pattern: pattern,
+			schema:  schema,
+		})
+	}
+
+	return annotationSchemas, nil
+}
+
+// Validates the value of a root key against every schema whose key or key
+// glob matches the key.
+func (s *AnnotationSchemas) Validate(key string, value interface{}) ([]SchemaViolation, error) {
+	violations := []SchemaViolation{}
+	if s == nil {
+		return violations, nil
+	}
+
+	for _, entry := range s.entries {
+		if matched, _ := path.Match(entry.pattern, key); !matched {
+			continue
+		}
+
+		err := entry.schema.Validate(value)
+		if err == nil {
+			continue
+		}
+
+		var validationErr *jsonschema.ValidationError
+		if !errors.As(err, &validationErr) {
+			return nil, err
+		}
+
+		for _, leaf := range leafValidationErrors(validationErr) {
+			violations = append(violations, SchemaViolation{
+				Pattern: entry.pattern,
+				Path:    fmt.Sprintf("[%q]", key) + pointerToIndexNotation(value, leaf.InstanceLocation),
+				Message: leaf.Message,
+			})
+		}
+	}
+
+	return violations, nil
+}
+
+// Only the innermost errors describe what is actually wrong, the rest merely
+// point at the schema that failed.
+func leafValidationErrors(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+
+	leaves := []*jsonschema.ValidationError{}
+	for _, cause := range err.Causes {
+		leaves = append(leaves, leafValidationErrors(cause)...)
+	}
+	return leaves
+}
+
+// Converts a JSON Pointer into the value into index notation. Array indexes
+// are only written as numbers when the value at that point is an array.
+func pointerToIndexNotation(value interface{}, pointer string) string {
+	if pointer == "" {
+		return ""
+	}
+
+	var output strings.Builder
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = ProcessString(token)
+		switch v := value.(type) {
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				value = nil
+				output.WriteString(fmt.Sprintf("[%q]", token))
+				continue
+			}
+			value = v[i]
+			output.WriteString(fmt.Sprintf("[%d]", i))
+		case map[string]interface{}:
+			value = v[token]
+			output.WriteString(fmt.Sprintf("[%q]", token))
+		default:
+			value = nil
+			output.WriteString(fmt.Sprintf("[%q]", token))
+		}
+	}
+	return output.String()
+}
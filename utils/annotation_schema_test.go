@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const devopsSchema = `{
+	"type": "object",
+	"required": ["status"],
+	"properties": {
+		"status": {"type": "boolean"},
+		"runs": {"type": "array", "items": {"type": "object", "properties": {"ok": {"type": "boolean"}}}}
+	}
+}`
+
+func TestAnnotationSchemasInline(t *testing.T) {
+	schemas, err := NewAnnotationSchemas(map[string]string{
+		"common/*": devopsSchema,
+	})
+	require.NoError(t, err)
+
+	valid := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(`{"status": true, "runs": [{"ok": true}]}`), &valid))
+	violations, err := schemas.Validate("common/devops", valid)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	invalid := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(`{"status": true, "runs": [{"ok": true}, {"ok": "yes"}]}`), &invalid))
+	violations, err = schemas.Validate("common/devops", invalid)
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "common/*", violations[0].Pattern)
+	assert.Equal(t, `["common/devops"]["runs"][1]["ok"]`, violations[0].Path)
+
+	violations, err = schemas.Validate("common/devops/status", "not checked")
+	require.NoError(t, err)
+	assert.Empty(t, violations, "Glob must not match across slashes.")
+}
+
+func TestAnnotationSchemasFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "devops.json")
+	require.NoError(t, os.WriteFile(file, []byte(devopsSchema), 0o600))
+
+	schemas, err := NewAnnotationSchemas(map[string]string{
+		"common/devops": file,
+	})
+	require.NoError(t, err)
+
+	violations, err := schemas.Validate("common/devops", map[string]interface{}{})
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, `["common/devops"]`, violations[0].Path)
+}
+
+func TestAnnotationSchemasInvalid(t *testing.T) {
+	_, err := NewAnnotationSchemas(map[string]string{"[": devopsSchema})
+	assert.Error(t, err, "Bad glob")
+
+	_, err = NewAnnotationSchemas(map[string]string{"common/devops": `{"type": 1}`})
+	assert.Error(t, err, "Bad schema")
+
+	_, err = NewAnnotationSchemas(map[string]string{"common/devops": "does-not-exist.json"})
+	assert.Error(t, err, "Missing file")
+}
+
+func TestNilAnnotationSchemasAcceptsEverything(t *testing.T) {
+	var schemas *AnnotationSchemas
+	violations, err := schemas.Validate("common/devops", nil)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
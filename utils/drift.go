@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"reflect"
+)
+
+// DriftKind describes how a root key changed outside Terraform.
+type DriftKind string
+
+const (
+	DriftRemoved      DriftKind = "removed"
+	DriftValueChanged DriftKind = "value_changed"
+	DriftTypeChanged  DriftKind = "type_changed"
+)
+
+// Compares the annotations known to Terraform with the ones read from the
+// backend. Returns the root keys of previous that were removed, or whose
+// value or JSON type changed. Root keys only present in current are ignored.
+func DetectDrift(previous, current map[string]interface{}) map[string]DriftKind {
+	drift := map[string]DriftKind{}
+	for k, previousValue := range previous {
+		currentValue, found := current[k]
+		switch {
+		case !found:
+			drift[k] = DriftRemoved
+		case jsonKind(previousValue) != jsonKind(currentValue):
+			drift[k] = DriftTypeChanged
+		case !reflect.DeepEqual(previousValue, currentValue):
+			drift[k] = DriftValueChanged
+		}
+	}
+	return drift
+}
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectDrift(t *testing.T) {
+	previous := map[string]interface{}{}
+	current := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"a": "x", "b": {"c": 1}, "d": true, "e": [1, 2], "f": "same"}`), &previous))
+	assert.NoError(t, json.Unmarshal([]byte(`{"b": {"c": 2}, "d": "true", "e": [1, 2], "f": "same", "g": "new"}`), &current))
+
+	assert.Equal(t, map[string]DriftKind{
+		"a": DriftRemoved,
+		"b": DriftValueChanged,
+		"d": DriftTypeChanged,
+	}, DetectDrift(previous, current), "Unchanged keys and keys only present on the backend should not be reported.")
+}
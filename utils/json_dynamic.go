@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var _ basetypes.DynamicTypable = JSONDynamicType{}
+var _ basetypes.DynamicValuableWithSemanticEquals = JSONDynamic{}
+
+// JSONDynamicType is a dynamic type for a JSON document written as a native
+// Terraform value instead of a jsonencode() string.
+type JSONDynamicType struct {
+	basetypes.DynamicType
+}
+
+func (t JSONDynamicType) String() string {
+	return "utils.JSONDynamicType"
+}
+
+func (t JSONDynamicType) Equal(o attr.Type) bool {
+	other, ok := o.(JSONDynamicType)
+	if !ok {
+		return false
+	}
+	return t.DynamicType.Equal(other.DynamicType)
+}
+
+func (t JSONDynamicType) ValueType(_ context.Context) attr.Value {
+	return JSONDynamic{}
+}
+
+func (t JSONDynamicType) ValueFromDynamic(_ context.Context, in basetypes.DynamicValue) (basetypes.DynamicValuable, diag.Diagnostics) {
+	return JSONDynamic{DynamicValue: in}, nil
+}
+
+func (t JSONDynamicType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.DynamicType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicValue, ok := attrValue.(basetypes.DynamicValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	return JSONDynamic{DynamicValue: dynamicValue}, nil
+}
+
+// JSONDynamic is a value of JSONDynamicType. Two values are semantically equal
+// if they convert to the same JSON, so that lists, sets and tuples with the
+// same elements, and maps and objects with the same attributes, are equal.
+type JSONDynamic struct {
+	basetypes.DynamicValue
+}
+
+func NewJSONDynamicNull() JSONDynamic {
+	return JSONDynamic{DynamicValue: basetypes.NewDynamicNull()}
+}
+
+func (v JSONDynamic) Type(_ context.Context) attr.Type {
+	return JSONDynamicType{}
+}
+
+func (v JSONDynamic) Equal(o attr.Value) bool {
+	other, ok := o.(JSONDynamic)
+	if !ok {
+		return false
+	}
+	return v.DynamicValue.Equal(other.DynamicValue)
+}
+
+// Returns false if the value or any value nested in it is unknown.
+func (v JSONDynamic) IsFullyKnown(ctx context.Context) bool {
+	tfValue, err := v.ToTerraformValue(ctx)
+	return err == nil && tfValue.IsFullyKnown()
+}
+
+func (v JSONDynamic) DynamicSemanticEquals(ctx context.Context, newValuable basetypes.DynamicValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(JSONDynamic)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T but got value type %T. Please report this to the provider developers.", v, newValuable),
+		)
+		return false, diags
+	}
+
+	if !v.IsFullyKnown(ctx) || !newValue.IsFullyKnown(ctx) {
+		return false, diags
+	}
+
+	oldJSON, err := TFTypesToBytes(v.DynamicValue)
+	if err != nil {
+		diags.AddError("Semantic Equality Check Error", err.Error())
+		return false, diags
+	}
+	newJSON, err := TFTypesToBytes(newValue.DynamicValue)
+	if err != nil {
+		diags.AddError("Semantic Equality Check Error", err.Error())
+		return false, diags
+	}
+
+	var oldObj, newObj interface{}
+	if err := json.Unmarshal(oldJSON, &oldObj); err != nil {
+		diags.AddError("Semantic Equality Check Error", err.Error())
+		return false, diags
+	}
+	if err := json.Unmarshal(newJSON, &newObj); err != nil {
+		diags.AddError("Semantic Equality Check Error", err.Error())
+		return false, diags
+	}
+	return reflect.DeepEqual(oldObj, newObj), diags
+}
+
+// AnnotationsValueRules applies the checks of MustBeMapOfString and
+// AnnotationsDataTypeRules to annotations written as a native Terraform value.
+type AnnotationsValueRules struct{}
+
+func (v AnnotationsValueRules) ValidateDynamic(ctx context.Context, req validator.DynamicRequest, resp *validator.DynamicResponse) {
+	// Unknown nested values would be converted to null.
+	if req.ConfigValue.IsNull() || !(JSONDynamic{DynamicValue: req.ConfigValue}).IsFullyKnown(ctx) {
+		return
+	}
+
+	jsonObj, err := TFTypesToJSON(req.ConfigValue)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Must be an object or a map. Key must be of string type.", err.Error())
+		return
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(jsonObj)) {
+		v := jsonObj[k]
+		if k == "" {
+			resp.Diagnostics.AddAttributeError(req.Path, "Object key cannot be empty string.", "")
+			continue
+		}
+
+		if v == nil {
+			resp.Diagnostics.AddAttributeError(req.Path, "Null value cannot be used for annotations.", fmt.Sprintf("Value for %s cannot be null", k))
+		}
+	}
+
+	for _, violation := range CheckDataTypeRules(jsonObj) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			violation.Summary,
+			fmt.Sprintf("Offending value at %s", violation.Path),
+		)
+	}
+}
+
+func (v AnnotationsValueRules) Description(ctx context.Context) string {
+	return "Must be an object or a map. " + AnnotationsDataTypeRules{}.Description(ctx)
+}
+
+func (v AnnotationsValueRules) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
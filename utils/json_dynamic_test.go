@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func annotationsObject(list attr.Value) JSONDynamic {
+	return JSONDynamic{DynamicValue: types.DynamicValue(types.ObjectValueMust(
+		map[string]attr.Type{"common/devops": list.Type(context.Background())},
+		map[string]attr.Value{"common/devops": list},
+	))}
+}
+
+func TestJSONDynamicListAndTupleAreEqual(t *testing.T) {
+	ctx := context.Background()
+	list := annotationsObject(types.ListValueMust(types.StringType, []attr.Value{types.StringValue("a"), types.StringValue("b")}))
+	tuple := annotationsObject(types.TupleValueMust(
+		[]attr.Type{types.StringType, types.StringType},
+		[]attr.Value{types.StringValue("a"), types.StringValue("b")},
+	))
+
+	equal, diags := list.DynamicSemanticEquals(ctx, tuple)
+	require.False(t, diags.HasError())
+	assert.True(t, equal, "A list and a tuple with the same elements should be equal.")
+
+	reordered := annotationsObject(types.TupleValueMust(
+		[]attr.Type{types.StringType, types.StringType},
+		[]attr.Value{types.StringValue("b"), types.StringValue("a")},
+	))
+	equal, diags = list.DynamicSemanticEquals(ctx, reordered)
+	require.False(t, diags.HasError())
+	assert.False(t, equal, "Elements in a different order should not be equal.")
+}
+
+func TestJSONDynamicUnknownIsNotEqual(t *testing.T) {
+	ctx := context.Background()
+	known := annotationsObject(types.StringValue("a"))
+	unknown := annotationsObject(types.StringUnknown())
+
+	assert.False(t, unknown.IsFullyKnown(ctx))
+	equal, diags := known.DynamicSemanticEquals(ctx, unknown)
+	require.False(t, diags.HasError())
+	assert.False(t, equal, "A partially unknown value should never be equal.")
+}
+
+func TestAnnotationsValueRules(t *testing.T) {
+	ctx := context.Background()
+	check := func(value basetypes.DynamicValue) int {
+		resp := &validator.DynamicResponse{}
+		AnnotationsValueRules{}.ValidateDynamic(ctx, validator.DynamicRequest{Path: path.Root("annotations_value"), ConfigValue: value}, resp)
+		return resp.Diagnostics.ErrorsCount()
+	}
+
+	assert.Equal(t, 0, check(annotationsObject(types.StringValue("a")).DynamicValue))
+	assert.Equal(t, 1, check(types.DynamicValue(types.StringValue("a"))), "A string is not an object.")
+	assert.Equal(t, 1, check(annotationsObject(types.TupleValueMust(
+		[]attr.Type{types.StringType, types.BoolType},
+		[]attr.Value{types.StringValue("a"), types.BoolValue(true)},
+	)).DynamicValue), "A tuple of mixed types breaks the data type rules.")
+}
@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// Splits an RFC 6901 JSON Pointer, e.g. "/common~1devops/team_a", into its
+// unescaped reference tokens.
+func ParseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("JSON Pointer %q must start with \"/\"", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = ProcessString(token)
+	}
+	return tokens, nil
+}
+
+// Joins reference tokens into an RFC 6901 JSON Pointer, the reverse of
+// ParseJSONPointer.
+func FormatJSONPointer(tokens []string) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteString("/")
+		sb.WriteString(EscapeString(token))
+	}
+	return sb.String()
+}
+
+// Returns the value at the tokens inside doc. Only objects are traversed.
+func GetJSONPointer(doc interface{}, tokens []string) (interface{}, bool) {
+	current := doc
+	for _, token := range tokens {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[token]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// Sets the value at the tokens inside doc, creating missing intermediate
+// objects. Fails if an intermediate value exists but is not an object.
+func SetJSONPointer(doc map[string]interface{}, tokens []string, value interface{}) error {
+	if len(tokens) == 0 {
+		return errors.New("cannot replace the whole document")
+	}
+
+	current := doc
+	for i, token := range tokens[:len(tokens)-1] {
+		next, found := current[token]
+		if !found {
+			next = map[string]interface{}{}
+			current[token] = next
+		}
+
+		object, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("value at %s is not an object", FormatJSONPointer(tokens[:i+1]))
+		}
+		current = object
+	}
+
+	current[tokens[len(tokens)-1]] = value
+	return nil
+}
+
+// Removes the value at the tokens inside doc. Returns false if there was
+// nothing to remove.
+func RemoveJSONPointer(doc map[string]interface{}, tokens []string) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+
+	parent, found := GetJSONPointer(doc, tokens[:len(tokens)-1])
+	if !found {
+		return false
+	}
+	object, ok := parent.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	if _, found := object[tokens[len(tokens)-1]]; !found {
+		return false
+	}
+
+	delete(object, tokens[len(tokens)-1])
+	return true
+}
+
+// NestedAnnotationPath requires a JSON Pointer to a value nested inside a
+// root key, e.g. "/common~1devops/team_a".
+type NestedAnnotationPath struct{}
+
+func (v NestedAnnotationPath) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	tokens, err := ParseJSONPointer(req.ConfigValue.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid JSON Pointer", err.Error())
+		return
+	}
+
+	if len(tokens) < 2 {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"JSON Pointer must point inside a root key",
+			"Use st-domain-management_domain_annotations to manage whole root keys.",
+		)
+		return
+	}
+
+	for _, token := range tokens {
+		if token == "" {
+			resp.Diagnostics.AddAttributeError(req.Path, "JSON Pointer must not contain empty keys", "")
+			return
+		}
+		if strings.ContainsAny(token, ".$") {
+			resp.Diagnostics.AddAttributeError(req.Path, "Key name must not contain \".\" or \"$\".", fmt.Sprintf("Offending key %q", token))
+			return
+		}
+	}
+}
+
+func (v NestedAnnotationPath) Description(_ context.Context) string {
+	return "Must be a JSON Pointer to a value nested inside a root key, e.g. \"/common~1devops/team_a\". " +
+		"Keys must not contain \".\" or \"$\"."
+}
+
+func (v NestedAnnotationPath) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// Returns a deep copy of a decoded JSON value, so that the copy can be
+// modified with SetJSONPointer and RemoveJSONPointer while the original is
+// kept.
+func CloneJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		clone := make(map[string]interface{}, len(v))
+		for k, nested := range v {
+			clone[k] = CloneJSON(nested)
+		}
+		return clone
+	case []interface{}:
+		clone := make([]interface{}, len(v))
+		for i, nested := range v {
+			clone[i] = CloneJSON(nested)
+		}
+		return clone
+	default:
+		return v
+	}
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJSONPointer(t *testing.T) {
+	assert := assert.New(t)
+
+	tokens, err := ParseJSONPointer("/common~1devops/team~0a")
+	assert.NoError(err)
+	assert.Equal([]string{"common/devops", "team~a"}, tokens)
+	assert.Equal("/common~1devops/team~0a", FormatJSONPointer(tokens))
+
+	_, err = ParseJSONPointer("common~1devops")
+	assert.Error(err, "Pointer without leading slash should be rejected.")
+}
+
+func TestSetJSONPointer(t *testing.T) {
+	assert := assert.New(t)
+
+	doc := map[string]interface{}{
+		"common/devops": map[string]interface{}{"team_b": "untouched"},
+	}
+	assert.NoError(SetJSONPointer(doc, []string{"common/devops", "team_a", "status"}, true))
+
+	value, found := GetJSONPointer(doc, []string{"common/devops", "team_a", "status"})
+	assert.True(found)
+	assert.Equal(true, value)
+
+	sibling, found := GetJSONPointer(doc, []string{"common/devops", "team_b"})
+	assert.True(found)
+	assert.Equal("untouched", sibling, "Siblings should be left untouched.")
+
+	err := SetJSONPointer(doc, []string{"common/devops", "team_b", "status"}, true)
+	assert.Error(err, "Traversing a non-object should fail.")
+}
+
+func TestRemoveJSONPointer(t *testing.T) {
+	assert := assert.New(t)
+
+	doc := map[string]interface{}{
+		"common/devops": map[string]interface{}{"team_a": "a", "team_b": "b"},
+	}
+	assert.True(RemoveJSONPointer(doc, []string{"common/devops", "team_a"}))
+	assert.False(RemoveJSONPointer(doc, []string{"common/devops", "team_a"}), "Removing twice should report nothing removed.")
+	assert.Equal(map[string]interface{}{"common/devops": map[string]interface{}{"team_b": "b"}}, doc)
+}
+
+func TestCloneJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	original := map[string]interface{}{
+		"team_a": map[string]interface{}{"tags": []interface{}{"a"}},
+	}
+	clone := CloneJSON(original).(map[string]interface{})
+	assert.NoError(SetJSONPointer(clone, []string{"team_a", "status"}, true))
+	clone["team_a"].(map[string]interface{})["tags"].([]interface{})[0] = "b"
+
+	assert.Equal(map[string]interface{}{
+		"team_a": map[string]interface{}{"tags": []interface{}{"a"}},
+	}, original, "The original should be left untouched.")
+}
+
+func TestNestedAnnotationPath(t *testing.T) {
+	validate := func(pointer string) diag.Diagnostics {
+		resp := &validator.StringResponse{}
+		NestedAnnotationPath{}.ValidateString(context.Background(), validator.StringRequest{
+			Path:        path.Root("path"),
+			ConfigValue: types.StringValue(pointer),
+		}, resp)
+		return resp.Diagnostics
+	}
+
+	assert.False(t, validate("/common~1devops/team_a").HasError())
+	assert.True(t, validate("/common~1devops").HasError(), "Pointer to a root key should be rejected.")
+	assert.True(t, validate("/common~1devops/team.a").HasError(), "Key with a dot should be rejected.")
+	assert.True(t, validate("/common.devops/team_a").HasError(), "Root key with a dot should be rejected.")
+	assert.True(t, validate("/common~1devops/$team").HasError(), "Key with a dollar sign should be rejected.")
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 )
@@ -50,3 +53,200 @@ func (v MustBeMapOfString) Description(_ context.Context) string {
 func (v MustBeMapOfString) MarkdownDescription(ctx context.Context) string {
 	return v.Description(ctx)
 }
+
+// AnnotationsDataTypeRules enforces the annotation data type rules from the
+// README on every nested key and value. Root level nulls and empty keys are
+// left to MustBeMapOfString.
+type AnnotationsDataTypeRules struct{}
+
+func (v AnnotationsDataTypeRules) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	var jsonObj map[string]interface{}
+
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	// Malformed JSON is already reported by MustBeMapOfString.
+	if err := json.Unmarshal([]byte(req.ConfigValue.ValueString()), &jsonObj); err != nil {
+		return
+	}
+
+	for _, violation := range CheckDataTypeRules(jsonObj) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			violation.Summary,
+			fmt.Sprintf("Offending value at %s", violation.Path),
+		)
+	}
+}
+
+func (v AnnotationsDataTypeRules) Description(_ context.Context) string {
+	return "Keys must not contain \".\" or \"$\". Nested values must not be null or empty. " +
+		"Lists must contain a single type, and objects in a list must have the same keys."
+}
+
+func (v AnnotationsDataTypeRules) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// AnnotationValueDataTypeRules enforces the annotation data type rules from
+// the README on a single value nested inside a root key, including the value
+// itself.
+type AnnotationValueDataTypeRules struct{}
+
+func (v AnnotationValueDataTypeRules) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	var value interface{}
+
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	// Malformed JSON is already reported by the JSON type.
+	if err := json.Unmarshal([]byte(req.ConfigValue.ValueString()), &value); err != nil {
+		return
+	}
+
+	for _, violation := range CheckValueDataTypeRules(value) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			violation.Summary,
+			fmt.Sprintf("Offending value at value%s", violation.Path),
+		)
+	}
+}
+
+func (v AnnotationValueDataTypeRules) Description(_ context.Context) string {
+	return "Must not be null or empty. Keys must not contain \".\" or \"$\". " +
+		"Lists must contain a single type, and objects in a list must have the same keys."
+}
+
+func (v AnnotationValueDataTypeRules) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// DataTypeViolation is a single breach of the annotation data type rules.
+// Path uses index notation, e.g. ["common/devops"]["list"][1].
+type DataTypeViolation struct {
+	Path    string
+	Summary string
+}
+
+// Recursively checks an annotations object against the data type rules
+// described in the README. Violations are returned in a stable order.
+func CheckDataTypeRules(obj map[string]interface{}) []DataTypeViolation {
+	violations := []DataTypeViolation{}
+	checkObject("", obj, true, &violations)
+	return violations
+}
+
+// Checks a single value nested inside a root key against the data type
+// rules. Paths are relative to the value, which itself has an empty path.
+func CheckValueDataTypeRules(value interface{}) []DataTypeViolation {
+	violations := []DataTypeViolation{}
+	checkValue("", value, &violations)
+	return violations
+}
+
+func checkObject(path string, obj map[string]interface{}, root bool, violations *[]DataTypeViolation) {
+	if len(obj) == 0 {
+		*violations = append(*violations, DataTypeViolation{Path: path, Summary: "Object must not be empty."})
+		return
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(obj)) {
+		keyPath := fmt.Sprintf("%s[%q]", path, k)
+		if strings.ContainsAny(k, ".$") {
+			*violations = append(*violations, DataTypeViolation{Path: keyPath, Summary: "Key name must not contain \".\" or \"$\"."})
+		}
+
+		if obj[k] == nil && root {
+			continue
+		}
+		checkValue(keyPath, obj[k], violations)
+	}
+}
+
+func checkValue(path string, value interface{}, violations *[]DataTypeViolation) {
+	switch value := value.(type) {
+	case nil:
+		*violations = append(*violations, DataTypeViolation{Path: path, Summary: "Value must not be null."})
+	case string:
+		if value == "" {
+			*violations = append(*violations, DataTypeViolation{Path: path, Summary: "Value must not be an empty string."})
+		}
+	case map[string]interface{}:
+		checkObject(path, value, false, violations)
+	case []interface{}:
+		checkArray(path, value, violations)
+	}
+}
+
+func checkArray(path string, array []interface{}, violations *[]DataTypeViolation) {
+	if len(array) == 0 {
+		*violations = append(*violations, DataTypeViolation{Path: path, Summary: "List must not be empty."})
+		return
+	}
+
+	for i, e := range array {
+		checkValue(fmt.Sprintf("%s[%d]", path, i), e, violations)
+	}
+
+	firstKind := jsonKind(array[0])
+	for i, e := range array[1:] {
+		if kind := jsonKind(e); kind != firstKind {
+			*violations = append(*violations, DataTypeViolation{
+				Path:    fmt.Sprintf("%s[%d]", path, i+1),
+				Summary: fmt.Sprintf("List must contain a single type. Expected %s, got %s.", firstKind, kind),
+			})
+			return
+		}
+	}
+
+	if firstKind != "object" {
+		return
+	}
+
+	firstShape := objectShape(array[0])
+	for i, e := range array[1:] {
+		if objectShape(e) != firstShape {
+			*violations = append(*violations, DataTypeViolation{
+				Path:    fmt.Sprintf("%s[%d]", path, i+1),
+				Summary: "Objects in a list must have exactly the same keys and nested keys.",
+			})
+		}
+	}
+}
+
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// Returns a signature of the keys and nested keys of an object, so that two
+// objects with the same signature have exactly the same key sets.
+func objectShape(value interface{}) string {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	keys := []string{}
+	for _, k := range slices.Sorted(maps.Keys(obj)) {
+		keys = append(keys, fmt.Sprintf("%q:%s", k, objectShape(obj[k])))
+	}
+	return "{" + strings.Join(keys, ",") + "}"
+}
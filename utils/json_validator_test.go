@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checkRules(t *testing.T, input string) []DataTypeViolation {
+	obj := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(input), &obj))
+	return CheckDataTypeRules(obj)
+}
+
+func TestDataTypeRulesValid(t *testing.T) {
+	violations := checkRules(t, `{
+		"common/devops": {
+			"status": true,
+			"list": [{"type": "weekday", "nested": {"a": 1}}, {"type": "weekend", "nested": {"a": 2}}],
+			"numbers": [1, 2, 3]
+		},
+		"common/devops/last-run": "yesterday"
+	}`)
+	assert.Empty(t, violations)
+}
+
+func TestDataTypeRulesKeyName(t *testing.T) {
+	violations := checkRules(t, `{"common/devops": {"a.b": 1, "$c": 2}, "common.dot": 3}`)
+	require.Len(t, violations, 3)
+	assert.Equal(t, `["common.dot"]`, violations[0].Path)
+	assert.Equal(t, `["common/devops"]["$c"]`, violations[1].Path)
+	assert.Equal(t, `["common/devops"]["a.b"]`, violations[2].Path)
+}
+
+func TestDataTypeRulesEmptyValues(t *testing.T) {
+	violations := checkRules(t, `{"a": {}, "b": [], "c": {"d": null, "e": ""}}`)
+	paths := []string{}
+	for _, v := range violations {
+		paths = append(paths, v.Path)
+	}
+	assert.Equal(t, []string{`["a"]`, `["b"]`, `["c"]["d"]`, `["c"]["e"]`}, paths)
+}
+
+func TestDataTypeRulesRootNullIsIgnored(t *testing.T) {
+	// Root level nulls are reported by MustBeMapOfString.
+	violations := checkRules(t, `{"a": null}`)
+	assert.Empty(t, violations)
+}
+
+func TestDataTypeRulesMixedList(t *testing.T) {
+	violations := checkRules(t, `{"a": {"list": [1, "two"]}}`)
+	require.Len(t, violations, 1)
+	assert.Equal(t, `["a"]["list"][1]`, violations[0].Path)
+	assert.Contains(t, violations[0].Summary, "single type")
+}
+
+func TestDataTypeRulesListObjectKeys(t *testing.T) {
+	violations := checkRules(t, `{"a": [{"x": {"y": 1}}, {"x": {"z": 1}}, {"x": {"y": 2}}]}`)
+	require.Len(t, violations, 1)
+	assert.Equal(t, `["a"][1]`, violations[0].Path)
+	assert.Contains(t, violations[0].Summary, "same keys")
+}
+
+func TestValueDataTypeRules(t *testing.T) {
+	for input, path := range map[string]string{
+		`null`:                        ``,
+		`{}`:                          ``,
+		`""`:                          ``,
+		`{"a.b": 1}`:                  `["a.b"]`,
+		`{"a": {"$b": true}}`:         `["a"]["$b"]`,
+		`{"a": [{"x": 1}, {"y": 1}]}`: `["a"][1]`,
+	} {
+		var value interface{}
+		require.NoError(t, json.Unmarshal([]byte(input), &value))
+
+		violations := CheckValueDataTypeRules(value)
+		require.Len(t, violations, 1, input)
+		assert.Equal(t, path, violations[0].Path, input)
+	}
+
+	assert.Empty(t, CheckValueDataTypeRules(map[string]interface{}{"status": true}))
+	assert.Empty(t, CheckValueDataTypeRules("on-call"))
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"errors"
+	"slices"
 	"strings"
 
 	"gomodules.xyz/jsonpatch/v2"
@@ -11,6 +12,9 @@ type UpdateOperations struct {
 	Create map[string]jsonpatch.Operation
 	Update map[string]jsonpatch.Operation
 	Delete map[string]jsonpatch.Operation
+	// The RFC 6902 patch the operations above were derived from, including
+	// nested paths and values.
+	Patch []jsonpatch.Operation
 }
 
 // Calculates the diff between state and plan objects. The output of this
@@ -34,6 +38,7 @@ func JSONDiffToTerraformOperations(state, plan []byte) (UpdateOperations, error)
 		Create: map[string]jsonpatch.Operation{},
 		Update: map[string]jsonpatch.Operation{},
 		Delete: map[string]jsonpatch.Operation{},
+		Patch:  patch,
 	}
 	for _, v := range patch {
 
@@ -79,6 +84,29 @@ func JSONDiffToTerraformOperations(state, plan []byte) (UpdateOperations, error)
 	return op, nil
 }
 
+// Returns the nested paths changed inside each root key, as JSON Pointers
+// relative to the annotations object, e.g. "/common~1devops/status". Root
+// keys that are created, deleted or replaced as a whole have no nested paths.
+func (op UpdateOperations) NestedPaths() map[string][]string {
+	paths := map[string][]string{}
+	for _, v := range op.Patch {
+		array := strings.Split(strings.Trim(v.Path, "/"), "/")
+		if len(array) < 2 {
+			continue
+		}
+
+		rootKey := ProcessString(array[0])
+		if !slices.Contains(paths[rootKey], v.Path) {
+			paths[rootKey] = append(paths[rootKey], v.Path)
+		}
+	}
+
+	for _, v := range paths {
+		slices.Sort(v)
+	}
+	return paths
+}
+
 // Process string according to RFC6902 standard
 // 1. "~0" will be converted back to "~"
 // 2. "~1" will be converted back to "/"
@@ -87,3 +115,12 @@ func ProcessString(input string) (output string) {
 	output = strings.ReplaceAll(output, "~0", "~")
 	return
 }
+
+// Escape string according to RFC6902 standard, the reverse of ProcessString
+// 1. "~" will be converted to "~0"
+// 2. "/" will be converted to "~1"
+func EscapeString(input string) (output string) {
+	output = strings.ReplaceAll(input, "~", "~0")
+	output = strings.ReplaceAll(output, "/", "~1")
+	return
+}
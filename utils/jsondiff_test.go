@@ -188,3 +188,45 @@ func TestDiffingOnEmptyString(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestPatchKeepsNestedPaths(t *testing.T) {
+	plan := json.RawMessage(`{"annotationA": "Hello", "annotationC/annotationD": {"annotationE":"Welcome Back"}}`)
+	state := json.RawMessage(`{"annotationA": "Hello", "annotationB": 69, "annotationC/annotationD": {"annotationE":"Bye"}}`)
+	test, err := JSONDiffToTerraformOperations(state, plan)
+	if err != nil {
+		t.Error(err)
+	}
+
+	assert := assert.New(t)
+	assert.Equal(2, len(test.Patch), "Count should be two.")
+
+	paths := map[string]string{}
+	for _, v := range test.Patch {
+		paths[v.Path] = v.Operation
+	}
+	assert.Equal("remove", paths["/annotationB"])
+	assert.Equal("replace", paths["/annotationC~1annotationD/annotationE"])
+}
+
+func TestEscapeStringRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("annotationB~01~1annotationC", EscapeString("annotationB~1/annotationC"))
+	assert.Equal("annotationB~1/annotationC", ProcessString(EscapeString("annotationB~1/annotationC")))
+}
+
+func TestNestedPaths(t *testing.T) {
+	plan := json.RawMessage(`{"annotationA": "Hi", "annotationC/annotationD": {"annotationE":"Welcome Back", "annotationF": {"annotationG": 1}}}`)
+	state := json.RawMessage(`{"annotationA": "Hello", "annotationB": 69, "annotationC/annotationD": {"annotationE":"Bye", "annotationF": {"annotationG": 2}}}`)
+	test, err := JSONDiffToTerraformOperations(state, plan)
+	if err != nil {
+		t.Error(err)
+	}
+
+	assert := assert.New(t)
+	assert.Equal(map[string][]string{
+		"annotationC/annotationD": {
+			"/annotationC~1annotationD/annotationE",
+			"/annotationC~1annotationD/annotationF/annotationG",
+		},
+	}, test.NestedPaths(), "Only nested changes should be listed, grouped by root key.")
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Lowercase RFC 1123 label. Kubernetes allows a whole DNS subdomain as label
+// prefix, but keys must not contain ".", so only a single label can be used.
+var dnsLabelRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+const dnsLabelMaxLength = 63
+
+// KeyPolicy is a naming policy for annotation keys. Keys follow the
+// Kubernetes style "prefix/name", where the prefix is everything before the
+// first slash. A nil KeyPolicy accepts every key.
+type KeyPolicy struct {
+	PrefixPattern      *regexp.Regexp
+	AllowedCharacters  *regexp.Regexp
+	MaxLength          int64
+	ReservedPrefixes   []string
+	DNSSubdomainPrefix bool
+}
+
+// Builds a KeyPolicy.
+//   - prefixPattern is a regular expression the whole prefix must match. If
+//     set, every key must have a prefix.
+//   - allowedCharacters is the content of a regular expression bracket
+//     expression, e.g. "a-z0-9/-", every character of the key must be in.
+//   - maxLength is the maximum length of the whole key. Zero means no limit.
+//   - reservedPrefixes are key prefixes that must not be used.
+//   - dnsSubdomainPrefix requires the prefix to be a valid DNS subdomain.
+//     Since keys must not contain ".", that is a single DNS label.
+func NewKeyPolicy(prefixPattern, allowedCharacters string, maxLength int64, reservedPrefixes []string, dnsSubdomainPrefix bool) (*KeyPolicy, error) {
+	policy := &KeyPolicy{
+		MaxLength:          maxLength,
+		ReservedPrefixes:   reservedPrefixes,
+		DNSSubdomainPrefix: dnsSubdomainPrefix,
+	}
+
+	if maxLength < 0 {
+		return nil, fmt.Errorf("max_length must not be negative, got %d", maxLength)
+	}
+
+	if prefixPattern != "" {
+		re, err := regexp.Compile(`^(?:` + prefixPattern + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prefix_pattern: %w", err)
+		}
+		policy.PrefixPattern = re
+	}
+
+	if allowedCharacters != "" {
+		re, err := regexp.Compile(`^[` + allowedCharacters + `]*$`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed_characters: %w", err)
+		}
+		policy.AllowedCharacters = re
+	}
+
+	return policy, nil
+}
+
+// Returns every way in which the key breaks the policy. An empty result
+// means the key is accepted.
+func (p *KeyPolicy) Check(key string) []string {
+	problems := []string{}
+	if p == nil {
+		return problems
+	}
+
+	prefix, _, hasPrefix := strings.Cut(key, "/")
+	if !hasPrefix {
+		prefix = ""
+	}
+
+	if p.MaxLength > 0 && int64(len(key)) > p.MaxLength {
+		problems = append(problems, fmt.Sprintf("Key must be at most %d characters long, got %d.", p.MaxLength, len(key)))
+	}
+
+	if p.AllowedCharacters != nil && !p.AllowedCharacters.MatchString(key) {
+		problems = append(problems, fmt.Sprintf("Key must only contain characters matching %s.", p.AllowedCharacters.String()))
+	}
+
+	if p.PrefixPattern != nil {
+		if !hasPrefix {
+			problems = append(problems, "Key must have a prefix, in the form of prefix/name.")
+		} else if !p.PrefixPattern.MatchString(prefix) {
+			problems = append(problems, fmt.Sprintf("Key prefix %q must match %s.", prefix, p.PrefixPattern.String()))
+		}
+	}
+
+	if p.DNSSubdomainPrefix && hasPrefix {
+		if strings.Contains(prefix, ".") {
+			problems = append(problems, fmt.Sprintf("Key prefix %q must be a single DNS label, since keys must not contain \".\".", prefix))
+		} else if len(prefix) > dnsLabelMaxLength || !dnsLabelRegexp.MatchString(prefix) {
+			problems = append(problems, fmt.Sprintf("Key prefix %q must be a valid lowercase DNS label.", prefix))
+		}
+	}
+
+	for _, reserved := range p.ReservedPrefixes {
+		if strings.HasPrefix(key, reserved) {
+			problems = append(problems, fmt.Sprintf("Key prefix %q is reserved.", reserved))
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNilKeyPolicyAcceptsEverything(t *testing.T) {
+	var policy *KeyPolicy
+	assert.Empty(t, policy.Check("anything goes"))
+}
+
+func TestKeyPolicyInvalidPattern(t *testing.T) {
+	_, err := NewKeyPolicy("(", "", 0, nil, false)
+	assert.Error(t, err)
+
+	_, err = NewKeyPolicy("", "a-", 0, nil, false)
+	assert.NoError(t, err)
+
+	_, err = NewKeyPolicy("", "", -1, nil, false)
+	assert.Error(t, err)
+}
+
+func TestKeyPolicyCheck(t *testing.T) {
+	policy, err := NewKeyPolicy("common|team-[a-z]+", "a-z0-9/-", 30, []string{"common/internal"}, true)
+	require.NoError(t, err)
+
+	assert.Empty(t, policy.Check("common/devops"))
+	assert.Empty(t, policy.Check("team-dns/devops/status"))
+
+	assert.Len(t, policy.Check("devops"), 1, "Missing prefix")
+	assert.Len(t, policy.Check("other/devops"), 1, "Prefix not matching pattern")
+	assert.Len(t, policy.Check("common/DevOps"), 1, "Character not allowed")
+	assert.Len(t, policy.Check("common/this-key-is-far-too-long-for-the-policy"), 1, "Too long")
+	assert.Len(t, policy.Check("common/internal-secret"), 1, "Reserved prefix")
+}
+
+func TestKeyPolicyDNSSubdomainPrefix(t *testing.T) {
+	policy, err := NewKeyPolicy("", "", 0, nil, true)
+	require.NoError(t, err)
+
+	assert.Empty(t, policy.Check("example/status"))
+	assert.Empty(t, policy.Check("no-prefix"))
+	assert.Len(t, policy.Check("Example/status"), 1)
+	assert.Len(t, policy.Check("-example/status"), 1)
+	assert.Len(t, policy.Check("example-/status"), 1)
+
+	// Keys must not contain ".", so a multi-label prefix can never be planned.
+	assert.Len(t, policy.Check("example.com/status"), 1)
+	assert.Len(t, policy.Check("example..com/status"), 1)
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+)
+
+type PolicySeverity string
+
+const (
+	PolicySeverityError   PolicySeverity = "error"
+	PolicySeverityWarning PolicySeverity = "warning"
+)
+
+// PolicyRule is a CEL expression that must evaluate to true for an
+// annotations change to be accepted. The expression can use the following
+// variables:
+//   - domain: the domain name, as a string.
+//   - labels: the current labels of the domain, as a map.
+//   - annotations: the planned annotations, as a map.
+type PolicyRule struct {
+	Name       string
+	Expression string
+	Message    string
+	Severity   PolicySeverity
+}
+
+// PolicyViolation is a rule that evaluated to false, or failed to evaluate.
+type PolicyViolation struct {
+	Rule   PolicyRule
+	Detail string
+}
+
+// Policy is a set of compiled CEL rules. A nil Policy accepts everything.
+type Policy struct {
+	rules    []PolicyRule
+	programs []cel.Program
+}
+
+func policyEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("domain", cel.StringType),
+		cel.Variable("labels", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("annotations", cel.MapType(cel.StringType, cel.DynType)),
+	)
+}
+
+// Compiles the rules. Every expression must evaluate to a bool.
+func NewPolicy(rules []PolicyRule) (*Policy, error) {
+	env, err := policyEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{}
+	for _, rule := range rules {
+		if rule.Severity == "" {
+			rule.Severity = PolicySeverityError
+		}
+		if rule.Severity != PolicySeverityError && rule.Severity != PolicySeverityWarning {
+			return nil, fmt.Errorf("rule %q: severity must be %q or %q, got %q", rule.Name, PolicySeverityError, PolicySeverityWarning, rule.Severity)
+		}
+
+		ast, issues := env.Compile(rule.Expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, issues.Err())
+		}
+		if outputType := ast.OutputType(); outputType != cel.BoolType && outputType != cel.DynType {
+			return nil, fmt.Errorf("rule %q: expression must evaluate to bool, got %s", rule.Name, outputType)
+		}
+
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+
+		policy.rules = append(policy.rules, rule)
+		policy.programs = append(policy.programs, program)
+	}
+
+	return policy, nil
+}
+
+// Returns true if there are rules to evaluate.
+func (p *Policy) HasRules() bool {
+	return p != nil && len(p.rules) > 0
+}
+
+// Evaluates every rule and returns the ones that did not evaluate to true.
+// A rule that fails to evaluate, e.g. by accessing a missing key, is also
+// considered violated.
+func (p *Policy) Evaluate(domain string, labels, annotations map[string]interface{}) []PolicyViolation {
+	violations := []PolicyViolation{}
+	if p == nil {
+		return violations
+	}
+
+	if labels == nil {
+		labels = map[string]interface{}{}
+	}
+	if annotations == nil {
+		annotations = map[string]interface{}{}
+	}
+
+	input := map[string]interface{}{
+		"domain":      domain,
+		"labels":      labels,
+		"annotations": annotations,
+	}
+
+	for i, program := range p.programs {
+		out, _, err := program.Eval(input)
+		if err != nil {
+			violations = append(violations, PolicyViolation{
+				Rule:   p.rules[i],
+				Detail: fmt.Sprintf("Failed to evaluate %s: %s", p.rules[i].Expression, err),
+			})
+			continue
+		}
+
+		if passed, ok := out.Value().(bool); !ok || !passed {
+			violations = append(violations, PolicyViolation{
+				Rule:   p.rules[i],
+				Detail: fmt.Sprintf("%s evaluated to false", p.rules[i].Expression),
+			})
+		}
+	}
+
+	return violations
+}
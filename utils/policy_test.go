@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var prodStatusRule = PolicyRule{
+	Name:       "prod-devops-status",
+	Expression: `!("common/env" in labels && labels["common/env"] == "prod" && "common/devops/status" in annotations && annotations["common/devops/status"] == false)`,
+	Message:    "Production domains must not have common/devops/status set to false.",
+	Severity:   PolicySeverityError,
+}
+
+func TestPolicyEvaluate(t *testing.T) {
+	policy, err := NewPolicy([]PolicyRule{prodStatusRule})
+	require.NoError(t, err)
+	assert.True(t, policy.HasRules())
+
+	prod := map[string]interface{}{"common/env": "prod"}
+	test := map[string]interface{}{"common/env": "test"}
+	disabled := map[string]interface{}{"common/devops/status": false}
+
+	assert.Empty(t, policy.Evaluate("example.com", test, disabled))
+	assert.Empty(t, policy.Evaluate("example.com", prod, map[string]interface{}{"common/devops/status": true}))
+	assert.Empty(t, policy.Evaluate("example.com", nil, nil))
+
+	violations := policy.Evaluate("example.com", prod, disabled)
+	require.Len(t, violations, 1)
+	assert.Equal(t, prodStatusRule.Name, violations[0].Rule.Name)
+}
+
+func TestPolicyEvaluationErrorIsViolation(t *testing.T) {
+	policy, err := NewPolicy([]PolicyRule{{
+		Name:       "missing-key",
+		Expression: `labels["common/env"] == "prod"`,
+		Severity:   PolicySeverityWarning,
+	}})
+	require.NoError(t, err)
+
+	violations := policy.Evaluate("example.com", nil, nil)
+	require.Len(t, violations, 1)
+	assert.Equal(t, PolicySeverityWarning, violations[0].Rule.Severity)
+}
+
+func TestPolicyInvalidRules(t *testing.T) {
+	_, err := NewPolicy([]PolicyRule{{Name: "syntax", Expression: `labels[`}})
+	assert.Error(t, err)
+
+	_, err = NewPolicy([]PolicyRule{{Name: "not-bool", Expression: `domain`}})
+	assert.Error(t, err)
+
+	_, err = NewPolicy([]PolicyRule{{Name: "severity", Expression: `true`, Severity: "fatal"}})
+	assert.Error(t, err)
+}
+
+func TestNilPolicy(t *testing.T) {
+	var policy *Policy
+	assert.False(t, policy.HasRules())
+	assert.Empty(t, policy.Evaluate("example.com", nil, nil))
+}
@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/big"
+	"reflect"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// Converts a json marshaled byte array following TypingStrict. The types are
+// worked out for the whole document first, so that sibling values, such as
+// the domains of a filter data source, can share a type even if some of them
+// hold empty arrays or objects.
+func strictJSONToTerraformDynamicValue(b []byte) (types.Dynamic, error) {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return types.Dynamic{}, fmt.Errorf("failed to unmarshal %s: %v", string(b), err)
+	}
+	if v == nil {
+		return types.DynamicNull(), nil
+	}
+
+	val, err := strictValue(v, strictType(v))
+	if err != nil {
+		return types.Dynamic{}, err
+	}
+	return types.DynamicValue(val), nil
+}
+
+// Returns the type of a value standing on its own.
+func strictType(v interface{}) attr.Type {
+	switch v := v.(type) {
+	case bool:
+		return types.BoolType
+	case float64:
+		return types.NumberType
+	case string:
+		return types.StringType
+	case []interface{}:
+		if elemType, ok := sharedType(v); ok {
+			return types.ListType{ElemType: elemType}
+		}
+		elemTypes := make([]attr.Type, len(v))
+		for i, e := range v {
+			elemTypes[i] = strictType(e)
+		}
+		return types.TupleType{ElemTypes: elemTypes}
+	case map[string]interface{}:
+		if elemType, ok := sharedType(slices.Collect(maps.Values(v))); ok {
+			return types.MapType{ElemType: elemType}
+		}
+		attrTypes := map[string]attr.Type{}
+		for k, e := range v {
+			attrTypes[k] = strictType(e)
+		}
+		return types.ObjectType{AttrTypes: attrTypes}
+	default:
+		return types.DynamicType
+	}
+}
+
+// Returns the single type every value can be converted to, so that the values
+// can be elements of one list or map. Empty arrays and objects take the type
+// of their siblings. Objects only share a type if they have the same keys, so
+// that no attributes are added to them. Nulls have no type of their own.
+func sharedType(values []interface{}) (attr.Type, bool) {
+	if len(values) == 0 {
+		return nil, false
+	}
+	if t, ok := unifiedType(values); ok {
+		return t, true
+	}
+
+	// Values that are typed the same on their own, e.g. tuples with the same
+	// element types, still share that type.
+	common := strictType(values[0])
+	if common.Equal(types.DynamicType) {
+		return nil, false
+	}
+	for _, v := range values[1:] {
+		if !common.Equal(strictType(v)) {
+			return nil, false
+		}
+	}
+	return common, true
+}
+
+func unifiedType(values []interface{}) (attr.Type, bool) {
+	kind := reflect.TypeOf(values[0])
+	if kind == nil {
+		return nil, false
+	}
+	for _, v := range values[1:] {
+		if reflect.TypeOf(v) != kind {
+			return nil, false
+		}
+	}
+
+	switch values[0].(type) {
+	case []interface{}:
+		elems := []interface{}{}
+		for _, v := range values {
+			elems = append(elems, v.([]interface{})...)
+		}
+		elemType, ok := sharedType(elems)
+		if !ok {
+			return nil, false
+		}
+		return types.ListType{ElemType: elemType}, true
+	case map[string]interface{}:
+		elems := []interface{}{}
+		columns := map[string][]interface{}{}
+		for _, v := range values {
+			for k, e := range v.(map[string]interface{}) {
+				elems = append(elems, e)
+				columns[k] = append(columns[k], e)
+			}
+		}
+		if elemType, ok := sharedType(elems); ok {
+			return types.MapType{ElemType: elemType}, true
+		}
+
+		attrTypes := map[string]attr.Type{}
+		for k, column := range columns {
+			if len(column) != len(values) {
+				return nil, false
+			}
+			attrType, ok := sharedType(column)
+			if !ok {
+				return nil, false
+			}
+			attrTypes[k] = attrType
+		}
+		return types.ObjectType{AttrTypes: attrTypes}, true
+	default:
+		return strictType(values[0]), true
+	}
+}
+
+// Converts the value into the given type, as worked out by strictType.
+func strictValue(v interface{}, t attr.Type) (attr.Value, error) {
+	if v == nil {
+		return nullValue(t)
+	}
+
+	switch t := t.(type) {
+	case types.ListType:
+		elems, err := strictValues(v.([]interface{}), func(int) attr.Type { return t.ElemType })
+		if err != nil {
+			return nil, err
+		}
+		val, diags := types.ListValue(t.ElemType, elems)
+		return val, diagsError(diags)
+	case types.TupleType:
+		elems, err := strictValues(v.([]interface{}), func(i int) attr.Type { return t.ElemTypes[i] })
+		if err != nil {
+			return nil, err
+		}
+		val, diags := types.TupleValue(t.ElemTypes, elems)
+		return val, diagsError(diags)
+	case types.MapType:
+		elems := map[string]attr.Value{}
+		for k, e := range v.(map[string]interface{}) {
+			var err error
+			if elems[k], err = strictValue(e, t.ElemType); err != nil {
+				return nil, err
+			}
+		}
+		val, diags := types.MapValue(t.ElemType, elems)
+		return val, diagsError(diags)
+	case types.ObjectType:
+		obj := v.(map[string]interface{})
+		attrVals := map[string]attr.Value{}
+		for k, attrType := range t.AttrTypes {
+			var err error
+			if attrVals[k], err = strictValue(obj[k], attrType); err != nil {
+				return nil, err
+			}
+		}
+		val, diags := types.ObjectValue(t.AttrTypes, attrVals)
+		return val, diagsError(diags)
+	}
+
+	switch v := v.(type) {
+	case bool:
+		return types.BoolValue(v), nil
+	case float64:
+		return types.NumberValue(big.NewFloat(v)), nil
+	case string:
+		return types.StringValue(v), nil
+	default:
+		return nil, fmt.Errorf("Unhandled type: %T", v)
+	}
+}
+
+func strictValues(in []interface{}, elemType func(int) attr.Type) ([]attr.Value, error) {
+	elems := make([]attr.Value, len(in))
+	for i, e := range in {
+		var err error
+		if elems[i], err = strictValue(e, elemType(i)); err != nil {
+			return nil, err
+		}
+	}
+	return elems, nil
+}
+
+// Returns a null of the given type.
+func nullValue(t attr.Type) (attr.Value, error) {
+	if t.Equal(types.DynamicType) {
+		return types.DynamicNull(), nil
+	}
+
+	ctx := context.Background()
+	return t.ValueFromTerraform(ctx, tftypes.NewValue(t.TerraformType(ctx), nil))
+}
+
+func diagsError(diags diag.Diagnostics) error {
+	if !diags.HasError() {
+		return nil
+	}
+	diag := diags.Errors()[0]
+	return fmt.Errorf("%s: %s", diag.Summary(), diag.Detail())
+}
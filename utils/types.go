@@ -9,15 +9,39 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
+// TypingMode controls how JSON arrays and objects are converted into
+// Terraform types.
+type TypingMode string
+
+const (
+	// TypingTuple converts every JSON array into a tuple and every JSON object
+	// into an object, regardless of the types of their elements.
+	TypingTuple TypingMode = "tuple"
+	// TypingStrict converts JSON arrays and objects whose elements all share
+	// the same type into lists and maps. Sibling values are typed together,
+	// so that they can share a type as well. Anything else falls back to
+	// tuples and objects.
+	TypingStrict TypingMode = "strict"
+)
+
 // Converts a json marshaled byte array into a Terraform Data with Dynamic Type
 //
 // Adapted from
 // https://github.com/magodo/terraform-provider-restful/blob/
 // eb875adeb0967a0a3cd7393d8eb2016a2642ac0f/internal/dynamic/dynamic.go#L284
 func JSONToTerraformDynamicValue(b []byte) (types.Dynamic, error) {
+	return JSONToTerraformDynamicValueWithMode(b, TypingTuple)
+}
+
+// Same as JSONToTerraformDynamicValue, but the conversion of arrays and
+// objects follows the given TypingMode.
+func JSONToTerraformDynamicValueWithMode(b []byte, mode TypingMode) (types.Dynamic, error) {
 	if len(b) == 0 {
 		return types.DynamicNull(), nil
 	}
+	if mode == TypingStrict {
+		return strictJSONToTerraformDynamicValue(b)
+	}
 	_, v, err := jsonToTFTypes(b)
 	if err != nil {
 		return types.Dynamic{}, err
@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -110,3 +113,149 @@ func TestTFTypesToJSON(t *testing.T) {
 
 	assert.Equal(t, jsonObj, actualJson)
 }
+
+func TestStrictTypingHomogeneous(t *testing.T) {
+	require := require.New(t)
+
+	jsonObj := map[string]interface{}{
+		"labels":  map[string]interface{}{"common/env": "prod", "common/brand": "a"},
+		"numbers": []interface{}{float64(1), float64(2), float64(3)},
+	}
+	bytes, err := json.Marshal(jsonObj)
+	require.NoError(err)
+
+	dynamicTFObj, err := JSONToTerraformDynamicValueWithMode(bytes, TypingStrict)
+	require.NoError(err)
+
+	root, ok := dynamicTFObj.UnderlyingValue().(types.Object)
+	require.True(ok, "Root with mixed value types should stay an object.")
+	assert.IsType(t, types.Map{}, root.Attributes()["labels"])
+	assert.IsType(t, types.List{}, root.Attributes()["numbers"])
+
+	actualJson, err := TFTypesToJSON(dynamicTFObj)
+	require.NoError(err)
+	assert.Equal(t, jsonObj, actualJson)
+}
+
+func TestStrictTypingHeterogeneous(t *testing.T) {
+	require := require.New(t)
+
+	jsonObj := map[string]interface{}{
+		"mixedArray": []interface{}{float64(1), "two", true},
+		"emptyArray": []interface{}{},
+		"nullArray":  []interface{}{"a", nil},
+	}
+	bytes, err := json.Marshal(jsonObj)
+	require.NoError(err)
+
+	dynamicTFObj, err := JSONToTerraformDynamicValueWithMode(bytes, TypingStrict)
+	require.NoError(err)
+
+	root, ok := dynamicTFObj.UnderlyingValue().(types.Object)
+	require.True(ok)
+	assert.IsType(t, types.Tuple{}, root.Attributes()["mixedArray"])
+	assert.IsType(t, types.Tuple{}, root.Attributes()["emptyArray"])
+	assert.IsType(t, types.Tuple{}, root.Attributes()["nullArray"])
+}
+
+func TestTupleTypingIsDefault(t *testing.T) {
+	require := require.New(t)
+
+	bytes, err := json.Marshal([]interface{}{"a", "b"})
+	require.NoError(err)
+
+	dynamicTFObj, err := JSONToTerraformDynamicValue(bytes)
+	require.NoError(err)
+	assert.IsType(t, types.Tuple{}, dynamicTFObj.UnderlyingValue())
+}
+
+func TestStrictTypingDomains(t *testing.T) {
+	require := require.New(t)
+
+	domains := `[
+		{
+			"domain": "a.com",
+			"metadata": {
+				"labels": {"common/brand": "a", "common/env": "prod"},
+				"annotations": {
+					"common/devops": {"team": "a", "oncall": ["x", "y"]}
+				}
+			}
+		},
+		{
+			"domain": "b.com",
+			"metadata": {
+				"labels": {},
+				"annotations": {
+					"common/devops": {"team": "b", "oncall": []}
+				}
+			}
+		},
+		{
+			"domain": "c.com",
+			"metadata": {
+				"labels": {"common/env": "dev"},
+				"annotations": {}
+			}
+		}
+	]`
+
+	dynamicTFObj, err := JSONToTerraformDynamicValueWithMode([]byte(domains), TypingStrict)
+	require.NoError(err)
+
+	list, ok := dynamicTFObj.UnderlyingValue().(types.List)
+	require.True(ok, "Domains should share a type despite empty labels and annotations.")
+	require.Len(list.Elements(), 3)
+
+	metadata := func(i int) map[string]attr.Value {
+		domain := list.Elements()[i].(types.Object)
+		return domain.Attributes()["metadata"].(types.Object).Attributes()
+	}
+	assert.IsType(t, types.Map{}, metadata(1)["labels"])
+	assert.Empty(t, metadata(1)["labels"].(types.Map).Elements())
+	assert.IsType(t, types.Map{}, metadata(2)["annotations"])
+	assert.Empty(t, metadata(2)["annotations"].(types.Map).Elements())
+	devops := metadata(1)["annotations"].(types.Map).Elements()["common/devops"].(types.Object)
+	assert.IsType(t, types.List{}, devops.Attributes()["oncall"])
+
+	actualJson, err := TFTypesToBytes(dynamicTFObj)
+	require.NoError(err)
+	assert.JSONEq(t, domains, string(actualJson))
+}
+
+func TestStrictTypingDomainsDifferentKeys(t *testing.T) {
+	require := require.New(t)
+
+	// The domains hold different root keys of different types, so they
+	// cannot share a type without adding keys to some of them.
+	domains := `[
+		{
+			"domain": "a.com",
+			"metadata": {
+				"labels": {"common/env": "prod"},
+				"annotations": {
+					"common/devops": {"team": "a", "oncall": ["x", "y"]},
+					"common/status": "live"
+				}
+			}
+		},
+		{
+			"domain": "c.com",
+			"metadata": {
+				"labels": {"common/env": "dev"},
+				"annotations": {}
+			}
+		}
+	]`
+
+	dynamicTFObj, err := JSONToTerraformDynamicValueWithMode([]byte(domains), TypingStrict)
+	require.NoError(err)
+
+	tuple, ok := dynamicTFObj.UnderlyingValue().(types.Tuple)
+	require.True(ok, "Domains with different keys should stay a tuple.")
+	require.Len(tuple.Elements(), 2)
+
+	actualJson, err := TFTypesToBytes(dynamicTFObj)
+	require.NoError(err)
+	assert.JSONEq(t, domains, string(actualJson))
+}